@@ -7,10 +7,16 @@ package ahocorasick
 */
 import "C"
 import (
+	"errors"
 	"runtime"
+	"sync"
+	"sync/atomic"
 	"unsafe"
 )
 
+// ErrClosed is the panic value used when searching with an automaton released by [AhoCorasick.Close].
+var ErrClosed = errors.New("ahocorasick: use of closed automaton")
+
 // Match represents a match found by an [AhoCorasick] automaton.
 type Match struct {
 	// The ending position of the match.
@@ -29,8 +35,26 @@ type Match struct {
 // However, there are a fair number of configurable options that can be set by using [AhoCorasickBuilder] instead.
 // Such options include, but are not limited to, how matches are determined, simple case insensitivity,
 // whether to use a AhoCorasickKindDFA or not and various knobs for controlling the space-vs-time trade-offs taken when building the automaton.
+//
+// The native memory held by an automaton is released when the automaton is garbage collected, or earlier by calling
+// [AhoCorasick.Close].
 type AhoCorasick struct {
-	automaton *C.AhoCorasick
+	anchored     *AhoCorasick
+	automaton    *C.AhoCorasick
+	closed       atomic.Bool
+	companion    *AhoCorasick
+	companionMu  sync.Mutex
+	config       Config
+	counters     *searchCounters
+	kind         AhoCorasickKind
+	name         string
+	nativeBytes  uint
+	observer     atomic.Pointer[Observer]
+	patternCount int
+	patterns     []string
+	prefixIndex  patternIndex
+	reversed     *AhoCorasick
+	tracking     *trackingRecord
 }
 
 // NewAhoCorasick creates a new Aho-Corasick automaton using the default configuration.
@@ -40,32 +64,100 @@ type AhoCorasick struct {
 //
 // This uses the default [matchkind.MatchKindStandard] match semantics, which reports a match as soon as it is found.
 // This corresponds to the standard match semantics supported by textbook descriptions of the Aho-Corasick algorithm.
+//
+// The automaton retains the patterns, as described on [AhoCorasickBuilder.Build].
+// NewAhoCorasick panics with [ErrBuildFailed] if the automaton could not be built.
 func NewAhoCorasick(patterns []string) *AhoCorasick {
+	var automaton *C.AhoCorasick
+	withCPatterns(patterns, func(cPatterns **C.char, cLengths *C.size_t, count C.size_t) {
+		automaton = C.create_automaton(cPatterns, cLengths, count)
+	})
+	if automaton == nil {
+		panic(ErrBuildFailed)
+	}
+	return newAhoCorasick(automaton, patterns, NewAhoCorasickBuilder())
+}
+
+// newAhoCorasick wraps a native automaton built by builder from patterns and arranges for it to be freed once the
+// wrapper is garbage collected. Automatons with a non-empty name are added to the registry reported by
+// [RegisteredAutomata].
+//
+// The patterns are retained (without copying the strings) so that derived automatons can be built on demand.
+func newAhoCorasick(automaton *C.AhoCorasick, patterns []string, builder *AhoCorasickBuilder) *AhoCorasick {
+	result := &AhoCorasick{
+		automaton:    automaton,
+		config:       builder.Config(),
+		counters:     &searchCounters{},
+		kind:         AhoCorasickKind(C.get_kind(automaton)),
+		name:         builder.name,
+		nativeBytes:  uint(C.memory_usage(automaton)),
+		patternCount: len(patterns),
+		patterns:     append([]string(nil), patterns...),
+	}
+	result.SetObserver(builder.observer)
+	if result.name != "" {
+		register(result)
+	}
+	result.tracking = track(result)
+	runtime.SetFinalizer(result, func(c *AhoCorasick) {
+		c.release(false)
+	})
+	return result
+}
+
+// release frees the native automaton and removes it from the registries, unless this was done already.
+func (ac *AhoCorasick) release(closed bool) {
+	if ac.closed.Swap(true) {
+		return
+	}
+	unregister(ac.counters)
+	untrack(ac, closed)
+	C.free_automaton(ac.automaton)
+	ac.companionMu.Lock()
+	for _, derived := range []*AhoCorasick{ac.anchored, ac.companion, ac.reversed} {
+		if derived != nil && closed {
+			derived.Close()
+		}
+	}
+	ac.companionMu.Unlock()
+}
+
+// native returns the native automaton, panicking with [ErrClosed] if it was already released by [AhoCorasick.Close].
+func (ac *AhoCorasick) native() *C.AhoCorasick {
+	if ac.closed.Load() {
+		panic(ErrClosed)
+	}
+	return ac.automaton
+}
+
+// withCPatterns pins the given patterns and passes them to fn as C arrays of pointers and lengths.
+// The arrays are only valid for the duration of the call.
+func withCPatterns(patterns []string, fn func(cPatterns **C.char, cLengths *C.size_t, count C.size_t)) {
 	pinner := runtime.Pinner{}
+	defer pinner.Unpin()
 	cPatterns := make([]*C.char, len(patterns))
 	cLengths := make([]C.size_t, len(patterns))
 	for i, pattern := range patterns {
-		data := unsafe.Pointer(&[]byte(pattern)[0])
+		data := unsafe.Pointer(unsafe.SliceData([]byte(pattern)))
 		pinner.Pin(data)
 		cPatterns[i] = (*C.char)(data)
 		cLengths[i] = C.size_t(len(pattern))
 	}
-	automaton := C.create_automaton(
-		(**C.char)(&cPatterns[0]),
-		(*C.size_t)(&cLengths[0]),
-		C.size_t(len(patterns)),
-	)
-	pinner.Unpin()
+	fn(unsafe.SliceData(cPatterns), unsafe.SliceData(cLengths), C.size_t(len(patterns)))
 	runtime.KeepAlive(cPatterns)
 	runtime.KeepAlive(cLengths)
 	runtime.KeepAlive(patterns)
-	result := &AhoCorasick{
-		automaton: automaton,
-	}
-	runtime.SetFinalizer(result, func(c *AhoCorasick) {
-		C.free_automaton(c.automaton)
-	})
-	return result
+}
+
+// Close releases the native memory held by this automaton without waiting for it to be garbage collected.
+//
+// Close must not be called while other goroutines are searching with this automaton; see [AtomicAutomaton] for a way
+// to replace automatons that are in use. Searching with a closed automaton panics with [ErrClosed].
+// Calling Close more than once has no effect. Close always returns nil.
+func (ac *AhoCorasick) Close() error {
+	ac.release(true)
+	runtime.SetFinalizer(ac, nil)
+	return nil
 }
 
 // FindAll returns an iterator of non-overlapping matches, using the match semantics that this automaton was constructed with.
@@ -73,13 +165,26 @@ func NewAhoCorasick(patterns []string) *AhoCorasick {
 // input may be any type that is cheaply convertible to an Input. This includes, but is not limited to, &str and &[u8].
 //
 // This is the infallible version of [AhoCorasick.TryFindIter].
+// FindAll panics with an error wrapping [ErrUnsupportedSearch] if this automaton only supports anchored searches.
 func (ac *AhoCorasick) FindAll(input string) []Match {
+	ac.requireUnanchored("FindAll")
+	span := ac.beginSearch(SearchMethodFindAll)
 	cText := (*C.char)(unsafe.Pointer(unsafe.StringData(input)))
 	foundCount := C.long(0)
-	cMatches := C.find_iter(ac.automaton, cText, C.size_t(len(input)), &foundCount)
+	cMatches := C.find_iter(ac.native(), cText, C.size_t(len(input)), &foundCount)
 	runtime.KeepAlive(cText)
 	runtime.KeepAlive(input)
 	runtime.KeepAlive(ac)
+	result := takeCMatches(cMatches, foundCount)
+	if ac.config.UTF8Aligned {
+		result = ac.realign(input, 0, len(input), result, -1, alignedIn(input))
+	}
+	span.end(len(input), len(result), false)
+	return result
+}
+
+// takeCMatches converts an array of matches returned by the native library and frees it.
+func takeCMatches(cMatches *C.AhoCorasickMatch, foundCount C.long) []Match {
 	result := make([]Match, int(foundCount))
 	if foundCount > 0 {
 		goSlice := (*[1 << 30]C.AhoCorasickMatch)(unsafe.Pointer(cMatches))[:foundCount:foundCount]
@@ -100,21 +205,34 @@ func (ac *AhoCorasick) FindAll(input string) []Match {
 // input may be any type that is cheaply convertible to an Input. This includes, but is not limited to, &str and &[u8].
 //
 // This is the infallible version of [AhoCorasick.TryFind].
+// FindFirst panics with an error wrapping [ErrUnsupportedSearch] if this automaton only supports anchored searches.
 func (ac *AhoCorasick) FindFirst(input string) *Match {
+	ac.requireUnanchored("FindFirst")
+	span := ac.beginSearch(SearchMethodFindFirst)
 	cText := (*C.char)(unsafe.Pointer(unsafe.StringData(input)))
-	match := C.find(ac.automaton, cText, C.size_t(len(input)))
+	match := C.find(ac.native(), cText, C.size_t(len(input)))
 	runtime.KeepAlive(cText)
 	runtime.KeepAlive(input)
 	runtime.KeepAlive(ac)
 	if match == nil {
+		span.end(len(input), 0, false)
 		return nil
 	}
 	defer C.free(unsafe.Pointer(match))
-	return &Match{
+	result := &Match{
 		End:          uint(match.end),
 		PatternIndex: uint(match.pattern_index),
 		Start:        uint(match.start),
 	}
+	if ac.config.UTF8Aligned {
+		result = firstOf(ac.realign(input, 0, len(input), []Match{*result}, 1, alignedIn(input)))
+		if result == nil {
+			span.end(len(input), 0, false)
+			return nil
+		}
+	}
+	span.end(len(input), 1, true)
+	return result
 }
 
 // GetKind returns the kind of the [AhoCorasick] automaton used by this searcher.
@@ -124,9 +242,35 @@ func (ac *AhoCorasick) FindFirst(input string) *Match {
 //
 // Note that the heuristics used for choosing which [ahocorasickkind.AhoCorasickKind] may be changed in a semver compatible release.
 func (ac *AhoCorasick) GetKind() AhoCorasickKind {
-	kind := C.get_kind(ac.automaton)
-	runtime.KeepAlive(ac)
-	return AhoCorasickKind(kind)
+	return ac.kind
+}
+
+// GetMatchKind returns the match semantics this automaton was built with.
+func (ac *AhoCorasick) GetMatchKind() MatchKind {
+	return ac.config.MatchKind
+}
+
+// GetName returns the name given to this automaton by [AhoCorasickBuilder.SetName], or an empty string.
+func (ac *AhoCorasick) GetName() string {
+	return ac.name
+}
+
+// GetObserver returns the [Observer] notified about searches performed by this automaton, or nil if there is none.
+func (ac *AhoCorasick) GetObserver() Observer {
+	if observer := ac.observer.Load(); observer != nil {
+		return *observer
+	}
+	return nil
+}
+
+// GetPatternCount returns the number of patterns this automaton was built with.
+func (ac *AhoCorasick) GetPatternCount() int {
+	return ac.patternCount
+}
+
+// GetStartKind returns the starting state configuration this automaton was built with.
+func (ac *AhoCorasick) GetStartKind() StartKind {
+	return ac.config.StartKind
 }
 
 // IsMatch returns true if and only if this automaton matches the haystack at any position.
@@ -139,11 +283,43 @@ func (ac *AhoCorasick) GetKind() AhoCorasickKind {
 //
 // Note that there is no corresponding fallible routine for this method. If you need a fallible version of this,
 // then [AhoCorasick.TryFind] can be used with Input::earliest enabled.
+//
+// IsMatch panics with an error wrapping [ErrUnsupportedSearch] if this automaton only supports anchored searches.
 func (ac *AhoCorasick) IsMatch(input string) bool {
+	ac.requireUnanchored("IsMatch")
+	span := ac.beginSearch(SearchMethodIsMatch)
 	cText := (*C.char)(unsafe.Pointer(unsafe.StringData(input)))
-	isMatch := C.is_match(ac.automaton, cText, C.size_t(len(input)))
+	isMatch := C.is_match(ac.native(), cText, C.size_t(len(input)))
 	runtime.KeepAlive(cText)
 	runtime.KeepAlive(input)
 	runtime.KeepAlive(ac)
-	return int(isMatch) != 0
+	found := int(isMatch) != 0
+	if found && ac.config.UTF8Aligned {
+		found = ac.findFirstIn(input, 0, len(input)) != nil
+	}
+	matchCount := 0
+	if found {
+		matchCount = 1
+	}
+	span.end(len(input), matchCount, found)
+	return found
+}
+
+// MemoryUsage returns the approximate number of bytes of native (Rust) heap memory used by this automaton.
+//
+// This memory is not managed by the Go garbage collector and is not reported by [runtime.MemStats].
+func (ac *AhoCorasick) MemoryUsage() uint {
+	return ac.nativeBytes
+}
+
+// SetObserver sets the [Observer] notified about every search performed by this automaton, replacing the one
+// inherited from [AhoCorasickBuilder.SetObserver], if any. Passing nil disables observation.
+//
+// It is safe to call SetObserver while other goroutines are searching.
+func (ac *AhoCorasick) SetObserver(observer Observer) {
+	if observer == nil {
+		ac.observer.Store(nil)
+		return
+	}
+	ac.observer.Store(&observer)
 }
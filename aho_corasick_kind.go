@@ -1,5 +1,7 @@
 package ahocorasick
 
+import "fmt"
+
 // AhoCorasickKind is principally used as an input to the [AhoCorasickBuilder.SetStartKind] method.
 // Its documentation goes into more detail about each choice.
 type AhoCorasickKind int
@@ -9,3 +11,36 @@ const (
 	AhoCorasickKindContinuousNFA    AhoCorasickKind = 2 // Use a contiguous NFA.
 	AhoCorasickKindDFA              AhoCorasickKind = 3 // Use a AhoCorasickKindDFA. Warning: DFAs typically use a large amount of memory.
 )
+
+// String returns the textual name of the automaton kind, e.g. "dfa".
+func (k AhoCorasickKind) String() string {
+	switch k {
+	case AhoCorasickKindNonContinuousNFA:
+		return "noncontiguous-nfa"
+	case AhoCorasickKindContinuousNFA:
+		return "contiguous-nfa"
+	case AhoCorasickKindDFA:
+		return "dfa"
+	}
+	return fmt.Sprintf("AhoCorasickKind(%d)", int(k))
+}
+
+// MarshalText implements [encoding.TextMarshaler] using the names returned by [AhoCorasickKind.String].
+func (k AhoCorasickKind) MarshalText() ([]byte, error) {
+	switch k {
+	case AhoCorasickKindNonContinuousNFA, AhoCorasickKindContinuousNFA, AhoCorasickKindDFA:
+		return []byte(k.String()), nil
+	}
+	return nil, fmt.Errorf("ahocorasick: invalid automaton kind %d", int(k))
+}
+
+// UnmarshalText implements [encoding.TextUnmarshaler]. It accepts the names returned by [AhoCorasickKind.String].
+func (k *AhoCorasickKind) UnmarshalText(text []byte) error {
+	for _, kind := range []AhoCorasickKind{AhoCorasickKindNonContinuousNFA, AhoCorasickKindContinuousNFA, AhoCorasickKindDFA} {
+		if string(text) == kind.String() {
+			*k = kind
+			return nil
+		}
+	}
+	return fmt.Errorf("ahocorasick: unknown automaton kind %q", text)
+}
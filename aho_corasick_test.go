@@ -1,6 +1,7 @@
 package ahocorasick
 
 import (
+	"errors"
 	"fmt"
 	. "github.com/smartystreets/goconvey/convey"
 	"testing"
@@ -102,3 +103,37 @@ func TestAhoCorasick(t *testing.T) {
 		})
 	})
 }
+
+func TestAnchoredOnlyAutomaton(t *testing.T) {
+	Convey("GIVEN an automaton supporting only anchored searches", t, func() {
+		automaton := NewAhoCorasickBuilder().SetStartKind(StartKindAnchored).Build([]string{"foo", "bar"})
+
+		Convey("THEN unanchored searches panic instead of aborting in the native library", func() {
+			for name, search := range map[string]func(){
+				"Count":     func() { automaton.Count("foo bar") },
+				"FindAll":   func() { automaton.FindAll("foo bar") },
+				"FindAllIn": func() { automaton.FindAllIn("foo bar", 0, 3) },
+				"FindFirst": func() { automaton.FindFirst("foo bar") },
+				"FindN":     func() { automaton.FindN("foo bar", 1) },
+				"IsMatch":   func() { automaton.IsMatch("foo bar") },
+				"Split":     func() { automaton.Split("foo bar", -1) },
+				"findAllIn": func() { automaton.findAllIn("foo bar", 0, 7) },
+			} {
+				func() {
+					defer func() {
+						err, _ := recover().(error)
+						So(errors.Is(err, ErrUnsupportedSearch), ShouldBeTrue)
+					}()
+					search()
+					t.Errorf("%s did not panic", name)
+				}()
+			}
+		})
+
+		Convey("THEN prefix lookups still work", func() {
+			match, ok := automaton.LongestPrefix("foo bar")
+			So(ok, ShouldBeTrue)
+			So(match, ShouldResemble, Match{End: 3, PatternIndex: 0, Start: 0})
+		})
+	})
+}
@@ -1,7 +1,16 @@
 #ifndef C_WRAPPER_H
 #define C_WRAPPER_H
 
+// The functions below are implemented by the aho_corasick_ffi library in the ffi directory of this repository.
+// Version 0.2.0 or later of that library is required: earlier versions only provide build_automaton,
+// create_automaton, find, find_iter, free_automaton, get_kind and is_match.
+//
+// The functions taking an error pointer set it to 1 instead of searching if the automaton does not support the
+// search, e.g. an unanchored search of an automaton that only supports anchored searches, and to 0 otherwise.
+// find, find_iter and is_match report no match in that case.
+
 #include <stddef.h>
+#include <stdint.h>
 
 typedef struct AhoCorasick AhoCorasick;
 
@@ -34,12 +43,30 @@ AhoCorasick* create_automaton(
     size_t num_patterns
 );
 
+size_t count_matches(
+    const AhoCorasick* automaton,
+    const char* text,
+    size_t text_len,
+    int overlapping,
+    size_t* pattern_counts,
+    int* error
+);
+
 AhoCorasickMatch* find(
     const AhoCorasick* automaton,
     const char* text,
     size_t text_len
 );
 
+AhoCorasickMatch* find_in(
+    const AhoCorasick* automaton,
+    const char* text,
+    size_t text_len,
+    size_t start,
+    size_t end,
+    int* error
+);
+
 AhoCorasickMatch* find_iter(
     const AhoCorasick* automaton,
     const char* text,
@@ -47,6 +74,49 @@ AhoCorasickMatch* find_iter(
     long* found_count
 );
 
+AhoCorasickMatch* find_iter_in(
+    const AhoCorasick* automaton,
+    const char* text,
+    size_t text_len,
+    size_t start,
+    size_t end,
+    long* found_count,
+    int* error
+);
+
+AhoCorasickMatch* find_iter_n(
+    const AhoCorasick* automaton,
+    const char* text,
+    size_t text_len,
+    size_t limit,
+    long* found_count,
+    int* truncated,
+    int* error
+);
+
+AhoCorasickMatch* find_prefix(
+    const AhoCorasick* automaton,
+    const char* text,
+    size_t text_len,
+    int* error
+);
+
+AhoCorasickMatch* find_prefixes(
+    const AhoCorasick* automaton,
+    const char* text,
+    size_t text_len,
+    long* found_count,
+    int* error
+);
+
+AhoCorasickMatch* find_overlapping_iter(
+    const AhoCorasick* automaton,
+    const char* text,
+    size_t text_len,
+    long* found_count,
+    int* error
+);
+
 void free_automaton(AhoCorasick* automaton);
 
 int get_kind(const AhoCorasick* automaton);
@@ -57,4 +127,24 @@ int is_match(
     size_t text_len
 );
 
+int is_match_in(
+    const AhoCorasick* automaton,
+    const char* text,
+    size_t text_len,
+    size_t start,
+    size_t end,
+    int* error
+);
+
+size_t memory_usage(const AhoCorasick* automaton);
+
+size_t which_match(
+    const AhoCorasick* automaton,
+    const char* text,
+    size_t text_len,
+    uint64_t* pattern_set,
+    size_t pattern_count,
+    int* error
+);
+
 #endif
@@ -0,0 +1,295 @@
+package ahocorasick
+
+import (
+	"sync/atomic"
+)
+
+// AtomicAutomaton holds an [AhoCorasick] automaton that can be replaced while other goroutines are searching with it.
+//
+// Searches always use the automaton that was current when they started. When an automaton is replaced using
+// [AtomicAutomaton.Swap], the old automaton is closed as soon as the searches that are still using it have finished,
+// so its native memory is released without waiting for the garbage collector and without racing with readers.
+//
+// Every search method of [AhoCorasick] has an equivalent on AtomicAutomaton. Other methods, and searches that must
+// all use the same automaton, are available through [AtomicAutomaton.With].
+//
+// An AtomicAutomaton takes ownership of the automatons given to it: they must not be closed by the caller,
+// nor be shared with other owners such as a [Cache].
+type AtomicAutomaton struct {
+	current atomic.Pointer[refCountedAutomaton]
+}
+
+// refCountedAutomaton counts the users of an automaton: one reference for being the current automaton of an
+// [AtomicAutomaton], and one for every search in progress. The automaton is closed when the count drops to zero.
+type refCountedAutomaton struct {
+	automaton *AhoCorasick
+	refs      atomic.Int64
+}
+
+func (r *refCountedAutomaton) release() {
+	if r.refs.Add(-1) == 0 {
+		r.automaton.Close()
+	}
+}
+
+// NewAtomicAutomaton creates an [AtomicAutomaton] whose current automaton is automaton.
+func NewAtomicAutomaton(automaton *AhoCorasick) *AtomicAutomaton {
+	result := &AtomicAutomaton{}
+	result.current.Store(newRefCountedAutomaton(automaton))
+	return result
+}
+
+func newRefCountedAutomaton(automaton *AhoCorasick) *refCountedAutomaton {
+	result := &refCountedAutomaton{automaton: automaton}
+	result.refs.Store(1)
+	return result
+}
+
+// Swap atomically makes automaton the current automaton. Searches started after Swap returns use the new automaton.
+//
+// The previous automaton is closed once all searches using it have finished.
+func (a *AtomicAutomaton) Swap(automaton *AhoCorasick) {
+	previous := a.current.Swap(newRefCountedAutomaton(automaton))
+	previous.release()
+}
+
+// Close closes the current automaton once all searches using it have finished.
+// The AtomicAutomaton must not be used afterwards.
+func (a *AtomicAutomaton) Close() error {
+	a.current.Load().release()
+	return nil
+}
+
+// With calls fn with the current automaton, which is guaranteed not to be closed until fn returns.
+//
+// This can be used to perform several searches with the same automaton, or to call methods of [AhoCorasick]
+// that [AtomicAutomaton] does not expose, such as [AhoCorasick.Stats] or [AhoCorasick.WithPatternFilter].
+// fn must not retain the automaton, nor anything referencing it, after it returns.
+func (a *AtomicAutomaton) With(fn func(ac *AhoCorasick)) {
+	current := a.acquire()
+	defer current.release()
+	fn(current.automaton)
+}
+
+// acquire returns the current automaton after taking a reference to it.
+func (a *AtomicAutomaton) acquire() *refCountedAutomaton {
+	for {
+		current := a.current.Load()
+		current.refs.Add(1)
+		if a.current.Load() == current {
+			return current
+		}
+		// The automaton was swapped out between loading it and taking the reference, so it may already be closed.
+		current.release()
+	}
+}
+
+// AllPrefixes is like [AhoCorasick.AllPrefixes] using the current automaton.
+func (a *AtomicAutomaton) AllPrefixes(input string) []Match {
+	current := a.acquire()
+	defer current.release()
+	return current.automaton.AllPrefixes(input)
+}
+
+// Count is like [AhoCorasick.Count] using the current automaton.
+func (a *AtomicAutomaton) Count(input string) int {
+	current := a.acquire()
+	defer current.release()
+	return current.automaton.Count(input)
+}
+
+// CountByPattern is like [AhoCorasick.CountByPattern] using the current automaton.
+func (a *AtomicAutomaton) CountByPattern(input string) []int {
+	current := a.acquire()
+	defer current.release()
+	return current.automaton.CountByPattern(input)
+}
+
+// CountOverlapping is like [AhoCorasick.CountOverlapping] using the current automaton.
+func (a *AtomicAutomaton) CountOverlapping(input string) int {
+	current := a.acquire()
+	defer current.release()
+	return current.automaton.CountOverlapping(input)
+}
+
+// CountOverlappingByPattern is like [AhoCorasick.CountOverlappingByPattern] using the current automaton.
+func (a *AtomicAutomaton) CountOverlappingByPattern(input string) []int {
+	current := a.acquire()
+	defer current.release()
+	return current.automaton.CountOverlappingByPattern(input)
+}
+
+// FindAll is like [AhoCorasick.FindAll] using the current automaton.
+func (a *AtomicAutomaton) FindAll(input string) []Match {
+	current := a.acquire()
+	defer current.release()
+	return current.automaton.FindAll(input)
+}
+
+// FindAllExcluding is like [AhoCorasick.FindAllExcluding] using the current automaton.
+func (a *AtomicAutomaton) FindAllExcluding(input string, excluded []Span) []Match {
+	current := a.acquire()
+	defer current.release()
+	return current.automaton.FindAllExcluding(input, excluded)
+}
+
+// FindAllFiltered is like [AhoCorasick.FindAllFiltered] using the current automaton.
+func (a *AtomicAutomaton) FindAllFiltered(input string, allowed *PatternSet) []Match {
+	current := a.acquire()
+	defer current.release()
+	return current.automaton.FindAllFiltered(input, allowed)
+}
+
+// FindAllIn is like [AhoCorasick.FindAllIn] using the current automaton.
+func (a *AtomicAutomaton) FindAllIn(input string, start int, end int) []Match {
+	current := a.acquire()
+	defer current.release()
+	return current.automaton.FindAllIn(input, start, end)
+}
+
+// FindAllPositions is like [AhoCorasick.FindAllPositions] using the current automaton.
+func (a *AtomicAutomaton) FindAllPositions(input string) []MatchPosition {
+	current := a.acquire()
+	defer current.release()
+	return current.automaton.FindAllPositions(input)
+}
+
+// FindFirst is like [AhoCorasick.FindFirst] using the current automaton.
+func (a *AtomicAutomaton) FindFirst(input string) *Match {
+	current := a.acquire()
+	defer current.release()
+	return current.automaton.FindFirst(input)
+}
+
+// FindFirstExcluding is like [AhoCorasick.FindFirstExcluding] using the current automaton.
+func (a *AtomicAutomaton) FindFirstExcluding(input string, excluded []Span) *Match {
+	current := a.acquire()
+	defer current.release()
+	return current.automaton.FindFirstExcluding(input, excluded)
+}
+
+// FindFirstFiltered is like [AhoCorasick.FindFirstFiltered] using the current automaton.
+func (a *AtomicAutomaton) FindFirstFiltered(input string, allowed *PatternSet) *Match {
+	current := a.acquire()
+	defer current.release()
+	return current.automaton.FindFirstFiltered(input, allowed)
+}
+
+// FindFirstIn is like [AhoCorasick.FindFirstIn] using the current automaton.
+func (a *AtomicAutomaton) FindFirstIn(input string, start int, end int) *Match {
+	current := a.acquire()
+	defer current.release()
+	return current.automaton.FindFirstIn(input, start, end)
+}
+
+// FindLast is like [AhoCorasick.FindLast] using the current automaton.
+func (a *AtomicAutomaton) FindLast(input string) *Match {
+	current := a.acquire()
+	defer current.release()
+	return current.automaton.FindLast(input)
+}
+
+// FindN is like [AhoCorasick.FindN] using the current automaton.
+func (a *AtomicAutomaton) FindN(input string, n int) ([]Match, bool) {
+	current := a.acquire()
+	defer current.release()
+	return current.automaton.FindN(input, n)
+}
+
+// FindOverlapping is like [AhoCorasick.FindOverlapping] using the current automaton.
+func (a *AtomicAutomaton) FindOverlapping(input string) []Match {
+	current := a.acquire()
+	defer current.release()
+	return current.automaton.FindOverlapping(input)
+}
+
+// GetKind is like [AhoCorasick.GetKind] using the current automaton.
+func (a *AtomicAutomaton) GetKind() AhoCorasickKind {
+	current := a.acquire()
+	defer current.release()
+	return current.automaton.GetKind()
+}
+
+// HasPrefix is like [AhoCorasick.HasPrefix] using the current automaton.
+func (a *AtomicAutomaton) HasPrefix(prefix string) bool {
+	current := a.acquire()
+	defer current.release()
+	return current.automaton.HasPrefix(prefix)
+}
+
+// Highlight is like [AhoCorasick.Highlight] using the current automaton.
+func (a *AtomicAutomaton) Highlight(input string, renderer HighlightRenderer) string {
+	current := a.acquire()
+	defer current.release()
+	return current.automaton.Highlight(input, renderer)
+}
+
+// IsMatch is like [AhoCorasick.IsMatch] using the current automaton.
+func (a *AtomicAutomaton) IsMatch(input string) bool {
+	current := a.acquire()
+	defer current.release()
+	return current.automaton.IsMatch(input)
+}
+
+// IsMatchExcluding is like [AhoCorasick.IsMatchExcluding] using the current automaton.
+func (a *AtomicAutomaton) IsMatchExcluding(input string, excluded []Span) bool {
+	current := a.acquire()
+	defer current.release()
+	return current.automaton.IsMatchExcluding(input, excluded)
+}
+
+// IsMatchFiltered is like [AhoCorasick.IsMatchFiltered] using the current automaton.
+func (a *AtomicAutomaton) IsMatchFiltered(input string, allowed *PatternSet) bool {
+	current := a.acquire()
+	defer current.release()
+	return current.automaton.IsMatchFiltered(input, allowed)
+}
+
+// IsMatchIn is like [AhoCorasick.IsMatchIn] using the current automaton.
+func (a *AtomicAutomaton) IsMatchIn(input string, start int, end int) bool {
+	current := a.acquire()
+	defer current.release()
+	return current.automaton.IsMatchIn(input, start, end)
+}
+
+// LongestPrefix is like [AhoCorasick.LongestPrefix] using the current automaton.
+func (a *AtomicAutomaton) LongestPrefix(input string) (Match, bool) {
+	current := a.acquire()
+	defer current.release()
+	return current.automaton.LongestPrefix(input)
+}
+
+// PatternsWithPrefix is like [AhoCorasick.PatternsWithPrefix] using the current automaton.
+func (a *AtomicAutomaton) PatternsWithPrefix(prefix string, limit int, order PatternOrder) []uint {
+	current := a.acquire()
+	defer current.release()
+	return current.automaton.PatternsWithPrefix(prefix, limit, order)
+}
+
+// ReplaceAllPreservingCase is like [AhoCorasick.ReplaceAllPreservingCase] using the current automaton.
+func (a *AtomicAutomaton) ReplaceAllPreservingCase(input string, replacements []string) string {
+	current := a.acquire()
+	defer current.release()
+	return current.automaton.ReplaceAllPreservingCase(input, replacements)
+}
+
+// Segments is like [AhoCorasick.Segments] using the current automaton.
+func (a *AtomicAutomaton) Segments(input string) []Segment {
+	current := a.acquire()
+	defer current.release()
+	return current.automaton.Segments(input)
+}
+
+// Split is like [AhoCorasick.Split] using the current automaton.
+func (a *AtomicAutomaton) Split(input string, n int) []string {
+	current := a.acquire()
+	defer current.release()
+	return current.automaton.Split(input, n)
+}
+
+// WhichMatch is like [AhoCorasick.WhichMatch] using the current automaton.
+func (a *AtomicAutomaton) WhichMatch(input string) *PatternSet {
+	current := a.acquire()
+	defer current.release()
+	return current.automaton.WhichMatch(input)
+}
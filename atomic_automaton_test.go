@@ -0,0 +1,91 @@
+package ahocorasick
+
+import (
+	"fmt"
+	. "github.com/smartystreets/goconvey/convey"
+	"runtime"
+	"sync"
+	"testing"
+)
+
+func ExampleAtomicAutomaton() {
+	blocklist := NewAtomicAutomaton(NewAhoCorasick([]string{"foo"}))
+	fmt.Println(blocklist.IsMatch("foo bar"), blocklist.IsMatch("bar baz"))
+	blocklist.Swap(NewAhoCorasick([]string{"baz"}))
+	fmt.Println(blocklist.IsMatch("foo bar"), blocklist.IsMatch("bar baz"))
+	// Output:
+	// true false
+	// false true
+}
+
+func TestAtomicAutomaton(t *testing.T) {
+	Convey("GIVEN an atomic automaton", t, func() {
+		first := NewAhoCorasick([]string{"foo"})
+		automaton := NewAtomicAutomaton(first)
+
+		Convey("WHEN it is swapped while a search is in progress", func() {
+			var inSearch, swapped sync.WaitGroup
+			inSearch.Add(1)
+			swapped.Add(1)
+			var closedDuringSearch bool
+			go automaton.With(func(ac *AhoCorasick) {
+				inSearch.Done()
+				swapped.Wait()
+				closedDuringSearch = ac.closed.Load()
+			})
+			inSearch.Wait()
+			automaton.Swap(NewAhoCorasick([]string{"bar"}))
+			swapped.Done()
+
+			Convey("THEN the old automaton is closed only after the search finished", func() {
+				automaton.With(func(ac *AhoCorasick) {
+					So(ac == first, ShouldBeFalse)
+				})
+				for !first.closed.Load() {
+					runtime.Gosched()
+				}
+				So(closedDuringSearch, ShouldBeFalse)
+			})
+		})
+
+		Convey("WHEN it is searched after a swap", func() {
+			current := NewAhoCorasickBuilder().SetMatchKind(MatchKindLeftMostLongest).Build([]string{"foo", "foobar", "bar"})
+			automaton.Swap(current)
+			haystack := "foobar foo bar"
+
+			Convey("THEN its search methods delegate to the current automaton", func() {
+				So(automaton.Count(haystack), ShouldEqual, current.Count(haystack))
+				So(automaton.CountOverlappingByPattern(haystack), ShouldResemble, current.CountOverlappingByPattern(haystack))
+				So(automaton.FindAllIn(haystack, 7, 14), ShouldResemble, current.FindAllIn(haystack, 7, 14))
+				So(automaton.FindLast(haystack), ShouldResemble, current.FindLast(haystack))
+				So(automaton.HasPrefix("foob"), ShouldBeTrue)
+				So(automaton.Split(haystack, -1), ShouldResemble, current.Split(haystack, -1))
+				So(automaton.WhichMatch(haystack).IDs(), ShouldResemble, current.WhichMatch(haystack).IDs())
+				match, ok := automaton.LongestPrefix(haystack)
+				So(ok, ShouldBeTrue)
+				So(match, ShouldResemble, Match{End: 6, PatternIndex: 1, Start: 0})
+			})
+		})
+
+		Convey("WHEN it is swapped concurrently with many searches", func() {
+			var wg sync.WaitGroup
+			for i := 0; i < 8; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for j := 0; j < 1000; j++ {
+						automaton.FindAll("foo bar")
+					}
+				}()
+			}
+			for i := 0; i < 100; i++ {
+				automaton.Swap(NewAhoCorasick([]string{"foo", fmt.Sprintf("bar_%d", i)}))
+			}
+			wg.Wait()
+
+			Convey("THEN no search uses a closed automaton", func() {
+				So(automaton.FindFirst("foo"), ShouldResemble, &Match{End: 3, PatternIndex: 0, Start: 0})
+			})
+		})
+	})
+}
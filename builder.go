@@ -7,10 +7,14 @@ package ahocorasick
 */
 import "C"
 import (
+	"errors"
 	"runtime"
 	"unsafe"
 )
 
+// ErrBuildFailed is returned by [AhoCorasickBuilder.TryBuild] when the native library could not build the automaton.
+var ErrBuildFailed = errors.New("ahocorasick: failed to build automaton")
+
 // AhoCorasickBuilder is a builder for configuring an [AhoCorasick] automaton.
 type AhoCorasickBuilder struct {
 	asciiCaseInsensitive bool
@@ -18,37 +22,46 @@ type AhoCorasickBuilder struct {
 	denseDepth           *uint
 	kind                 *AhoCorasickKind
 	matchKind            MatchKind
+	name                 string
+	observer             Observer
 	prefilter            bool
 	startKind            StartKind
+	utf8Aligned          bool
 }
 
 // NewAhoCorasickBuilder creates a new builder for configuring an [AhoCorasick] automaton.
 //
 // The builder provides a way to configure a number of things, including ASCII case insensitivity and what kind of match semantics are used.
 func NewAhoCorasickBuilder() *AhoCorasickBuilder {
-	return &AhoCorasickBuilder{
-		asciiCaseInsensitive: false,
-		byteClasses:          true,
-		denseDepth:           nil,
-		kind:                 nil,
-		matchKind:            MatchKindStandard,
-		prefilter:            true,
-		startKind:            StartKindUnanchored,
-	}
+	return NewAhoCorasickBuilderFromConfig(DefaultConfig())
 }
 
 // Build creates an [AhoCorasick] automaton using the configuration set on this builder.
 //
 // A builder may be reused to create more automatons.
+//
+// The automaton keeps a reference to every pattern for its whole lifetime, so that derived automatons can be built
+// when they are first needed. This keeps the memory of the patterns alive even if the caller drops them after Build,
+// plus a 16-byte string header per pattern; the strings themselves are not copied.
+//
+// This is the infallible version of [AhoCorasickBuilder.TryBuild]. It panics if the automaton could not be built.
 func (b *AhoCorasickBuilder) Build(patterns []string) *AhoCorasick {
-	pinner := runtime.Pinner{}
-	cPatterns := make([]*C.char, len(patterns))
-	cLengths := make([]C.size_t, len(patterns))
-	for i, pattern := range patterns {
-		data := unsafe.Pointer(&[]byte(pattern)[0])
-		pinner.Pin(data)
-		cPatterns[i] = (*C.char)(data)
-		cLengths[i] = C.size_t(len(pattern))
+	automaton, err := b.TryBuild(patterns)
+	if err != nil {
+		panic(err)
+	}
+	return automaton
+}
+
+// TryBuild creates an [AhoCorasick] automaton using the configuration set on this builder.
+//
+// An error is returned if the configuration is invalid (see [Config.Validate]) or if the automaton could not be built,
+// for example because an explicitly requested [AhoCorasickKind] exceeds its size limits.
+//
+// Like [AhoCorasickBuilder.Build], the automaton retains the patterns.
+func (b *AhoCorasickBuilder) TryBuild(patterns []string) (*AhoCorasick, error) {
+	if err := b.Config().Validate(); err != nil {
+		return nil, err
 	}
 	options := C.AhoCorasickBuilderOptions{
 		ascii_case_insensitive: boolToCInt(b.asciiCaseInsensitive),
@@ -59,20 +72,86 @@ func (b *AhoCorasickBuilder) Build(patterns []string) *AhoCorasick {
 		prefilter:              boolToCInt(b.prefilter),
 		start_kind:             C.size_t(b.startKind),
 	}
-	automaton := C.build_automaton(
-		(**C.char)(&cPatterns[0]),
-		(*C.size_t)(&cLengths[0]),
-		C.size_t(len(patterns)),
-		(*C.AhoCorasickBuilderOptions)(unsafe.Pointer(&options)),
-	)
-	runtime.KeepAlive(patterns)
-	runtime.KeepAlive(cPatterns)
-	runtime.KeepAlive(cLengths)
+	pinner := runtime.Pinner{}
+	defer pinner.Unpin()
+	if options.dense_depth != nil {
+		pinner.Pin(options.dense_depth)
+	}
+	if options.kind != nil {
+		pinner.Pin(options.kind)
+	}
+	var automaton *C.AhoCorasick
+	withCPatterns(patterns, func(cPatterns **C.char, cLengths *C.size_t, count C.size_t) {
+		automaton = C.build_automaton(cPatterns, cLengths, count, &options)
+	})
 	runtime.KeepAlive(options)
-	pinner.Unpin()
-	return &AhoCorasick{
-		automaton: automaton,
+	runtime.KeepAlive(b)
+	if automaton == nil {
+		return nil, ErrBuildFailed
 	}
+	return newAhoCorasick(automaton, patterns, b), nil
+}
+
+// Clone returns a copy of this builder. Changes made to the copy do not affect this builder and vice versa.
+func (b *AhoCorasickBuilder) Clone() *AhoCorasickBuilder {
+	clone := NewAhoCorasickBuilderFromConfig(b.Config())
+	clone.name = b.name
+	clone.observer = b.observer
+	return clone
+}
+
+// GetAsciiCaseInsensitive returns whether ASCII-aware case-insensitive matching is enabled.
+// See [AhoCorasickBuilder.SetAsciiCaseInsensitive].
+func (b *AhoCorasickBuilder) GetAsciiCaseInsensitive() bool {
+	return b.asciiCaseInsensitive
+}
+
+// GetByteClasses returns whether the automaton's alphabet is shrunk using byte classes.
+// See [AhoCorasickBuilder.SetByteClasses].
+func (b *AhoCorasickBuilder) GetByteClasses() bool {
+	return b.byteClasses
+}
+
+// GetDenseDepth returns a copy of the configured dense depth, or nil if the default is used.
+// See [AhoCorasickBuilder.SetDenseDepth].
+func (b *AhoCorasickBuilder) GetDenseDepth() *uint {
+	return copyPtr(b.denseDepth)
+}
+
+// GetKind returns a copy of the configured automaton kind, or nil if the kind is chosen automatically.
+// See [AhoCorasickBuilder.SetKind].
+func (b *AhoCorasickBuilder) GetKind() *AhoCorasickKind {
+	return copyPtr(b.kind)
+}
+
+// GetMatchKind returns the configured match semantics. See [AhoCorasickBuilder.SetMatchKind].
+func (b *AhoCorasickBuilder) GetMatchKind() MatchKind {
+	return b.matchKind
+}
+
+// GetName returns the name given to automatons built by this builder. See [AhoCorasickBuilder.SetName].
+func (b *AhoCorasickBuilder) GetName() string {
+	return b.name
+}
+
+// GetObserver returns the [Observer] given to automatons built by this builder, or nil if there is none.
+func (b *AhoCorasickBuilder) GetObserver() Observer {
+	return b.observer
+}
+
+// GetPrefilter returns whether heuristic prefilter optimizations are enabled. See [AhoCorasickBuilder.SetPrefilter].
+func (b *AhoCorasickBuilder) GetPrefilter() bool {
+	return b.prefilter
+}
+
+// GetStartKind returns the configured starting state configuration. See [AhoCorasickBuilder.SetStartKind].
+func (b *AhoCorasickBuilder) GetStartKind() StartKind {
+	return b.startKind
+}
+
+// GetUTF8Aligned returns whether only matches on UTF-8 boundaries are reported. See [AhoCorasickBuilder.SetUTF8Aligned].
+func (b *AhoCorasickBuilder) GetUTF8Aligned() bool {
+	return b.utf8Aligned
 }
 
 // SetAsciiCaseInsensitive enables ASCII-aware case-insensitive matching.
@@ -191,6 +270,25 @@ func (b *AhoCorasickBuilder) SetMatchKind(matchKind MatchKind) *AhoCorasickBuild
 	return b
 }
 
+// SetName sets a name identifying the automatons built by this builder in diagnostics.
+//
+// Named automatons label the runtime/trace regions of their searches with their name and are listed by
+// [RegisteredAutomata] (and therefore by [PublishExpvar]) for as long as they are alive.
+// The name does not need to be unique. The default is an empty name, which leaves automatons unregistered.
+func (b *AhoCorasickBuilder) SetName(name string) *AhoCorasickBuilder {
+	b.name = name
+	return b
+}
+
+// SetObserver sets the [Observer] notified about every search performed by automatons built by this builder.
+//
+// The observer is not part of [Config], since it cannot be described by a configuration file.
+// Passing nil (the default) disables observation.
+func (b *AhoCorasickBuilder) SetObserver(observer Observer) *AhoCorasickBuilder {
+	b.observer = observer
+	return b
+}
+
 // SetPrefilter enables heuristic prefilter optimizations.
 //
 // When enabled, searching will attempt to quickly skip to match candidates using specialized literal search routines.
@@ -231,6 +329,25 @@ func (b *AhoCorasickBuilder) SetStartKind(startKind StartKind) *AhoCorasickBuild
 	return b
 }
 
+// SetUTF8Aligned sets whether matches that begin or end in the middle of a UTF-8 encoded character of the haystack
+// are rejected.
+//
+// Such matches are possible with patterns that are not valid UTF-8 on their own, e.g. a pattern made of UTF-8
+// continuation bytes, or with ASCII case insensitivity. When this option is enabled, a match is only reported if
+// neither its start nor its end is right before a UTF-8 continuation byte of the haystack.
+//
+// Rejected candidates are dropped during the search rather than filtered out of its results, so they never hide
+// valid matches: the search reports the matches that an automaton without the rejected candidates would report.
+// This applies to every search of the automaton, including overlapping searches, counts and prefix lookups.
+// When a search finds no misaligned match, this costs a single scan of the matches. Otherwise, the matches are
+// selected from all aligned overlapping matches, like [AhoCorasick.FindAllFiltered] does.
+//
+// This is disabled by default.
+func (b *AhoCorasickBuilder) SetUTF8Aligned(utf8Aligned bool) *AhoCorasickBuilder {
+	b.utf8Aligned = utf8Aligned
+	return b
+}
+
 func boolToCInt(b bool) C.int {
 	if b {
 		return 1
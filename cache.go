@@ -0,0 +1,217 @@
+package ahocorasick
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"sync"
+)
+
+// Fingerprint identifies a list of patterns together with the builder settings used to build an automaton from them.
+//
+// Two fingerprints are equal if and only if (barring hash collisions) they describe the same ordered list of patterns,
+// the same [Config] and the same name. The fingerprint is stable across processes and releases of this package.
+type Fingerprint [sha256.Size]byte
+
+// String returns the fingerprint as a hexadecimal string.
+func (f Fingerprint) String() string {
+	return hex.EncodeToString(f[:])
+}
+
+// FingerprintOf returns the [Fingerprint] of the automaton that builder would build from patterns.
+//
+// The observer of the builder is not part of the fingerprint.
+func FingerprintOf(builder *AhoCorasickBuilder, patterns []string) Fingerprint {
+	hash := sha256.New()
+	var buf [binary.MaxVarintLen64]byte
+	writeUint := func(value uint64) {
+		hash.Write(buf[:binary.PutUvarint(buf[:], value)])
+	}
+	writeString := func(value string) {
+		writeUint(uint64(len(value)))
+		hash.Write([]byte(value))
+	}
+	writeBool := func(value bool) {
+		if value {
+			writeUint(1)
+		} else {
+			writeUint(0)
+		}
+	}
+
+	config := builder.Config()
+	writeString("ahocorasick fingerprint v1")
+	writeBool(config.AsciiCaseInsensitive)
+	writeBool(config.ByteClasses)
+	writeBool(config.DenseDepth != nil)
+	if config.DenseDepth != nil {
+		writeUint(uint64(*config.DenseDepth))
+	}
+	writeBool(config.Kind != nil)
+	if config.Kind != nil {
+		writeUint(uint64(*config.Kind))
+	}
+	writeUint(uint64(config.MatchKind))
+	writeBool(config.Prefilter)
+	writeUint(uint64(config.StartKind))
+	writeBool(config.UTF8Aligned)
+	writeString(builder.name)
+	writeUint(uint64(len(patterns)))
+	for _, pattern := range patterns {
+		writeString(pattern)
+	}
+
+	var fingerprint Fingerprint
+	hash.Sum(fingerprint[:0])
+	return fingerprint
+}
+
+// Cache is a concurrency-safe cache of automatons keyed by their [Fingerprint].
+//
+// The cache evicts the least recently used automatons once the total native memory used by the cached automatons
+// (see [AhoCorasick.MemoryUsage]) exceeds its budget. Evicted automatons are not closed, since they may still be in use;
+// their memory is released once they are garbage collected.
+//
+// Concurrent requests for the same fingerprint build the automaton only once.
+type Cache struct {
+	maxBytes uint
+
+	mu        sync.Mutex
+	usedBytes uint
+	entries   map[Fingerprint]*list.Element
+	lru       *list.List
+	inflight  map[Fingerprint]*cacheCall
+}
+
+// cacheEntry is the value of an element of [Cache.lru].
+type cacheEntry struct {
+	fingerprint Fingerprint
+	automaton   *AhoCorasick
+}
+
+// cacheCall is an in-flight build whose result is shared by all callers requesting the same fingerprint.
+type cacheCall struct {
+	done      chan struct{}
+	automaton *AhoCorasick
+	err       error
+}
+
+// NewCache creates an empty cache that keeps at most maxBytes of native memory in cached automatons.
+//
+// An automaton that is larger than maxBytes on its own is returned to the caller but never cached.
+func NewCache(maxBytes uint) *Cache {
+	return &Cache{
+		maxBytes: maxBytes,
+		entries:  make(map[Fingerprint]*list.Element),
+		lru:      list.New(),
+		inflight: make(map[Fingerprint]*cacheCall),
+	}
+}
+
+// Get returns the cached automaton for builder and patterns, building and caching it using
+// [AhoCorasickBuilder.TryBuild] if it is not cached yet.
+//
+// Build errors are returned to every caller waiting for the build, and are not cached. If the build panics, the panic
+// is propagated to the caller that started it and the other callers get an error wrapping [ErrBuildFailed].
+// The returned automaton is shared, so it must not be closed by the caller.
+func (c *Cache) Get(builder *AhoCorasickBuilder, patterns []string) (*AhoCorasick, error) {
+	fingerprint := FingerprintOf(builder, patterns)
+
+	c.mu.Lock()
+	if element, ok := c.entries[fingerprint]; ok {
+		c.lru.MoveToFront(element)
+		c.mu.Unlock()
+		return element.Value.(*cacheEntry).automaton, nil
+	}
+	if call, ok := c.inflight[fingerprint]; ok {
+		c.mu.Unlock()
+		<-call.done
+		return call.automaton, call.err
+	}
+	call := &cacheCall{done: make(chan struct{})}
+	c.inflight[fingerprint] = call
+	c.mu.Unlock()
+
+	c.build(fingerprint, call, func() (*AhoCorasick, error) {
+		return builder.TryBuild(patterns)
+	})
+	return call.automaton, call.err
+}
+
+// build runs the in-flight build call and publishes its result to the callers waiting for it.
+//
+// If build panics, the waiting callers get an error wrapping [ErrBuildFailed] and the panic is propagated to the caller.
+func (c *Cache) build(fingerprint Fingerprint, call *cacheCall, build func() (*AhoCorasick, error)) {
+	defer func() {
+		r := recover()
+		if r != nil {
+			call.automaton, call.err = nil, fmt.Errorf("%w: %v", ErrBuildFailed, r)
+		}
+		c.mu.Lock()
+		delete(c.inflight, fingerprint)
+		if call.err == nil {
+			c.add(fingerprint, call.automaton)
+		}
+		c.mu.Unlock()
+		close(call.done)
+		if r != nil {
+			panic(r)
+		}
+	}()
+	call.automaton, call.err = build()
+}
+
+// Len returns the number of cached automatons.
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
+
+// Bytes returns the total native memory used by the cached automatons.
+func (c *Cache) Bytes() uint {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.usedBytes
+}
+
+// Remove removes the automaton with the given fingerprint from the cache, if present.
+func (c *Cache) Remove(fingerprint Fingerprint) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if element, ok := c.entries[fingerprint]; ok {
+		c.remove(element)
+	}
+}
+
+// Purge removes all automatons from the cache.
+func (c *Cache) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = make(map[Fingerprint]*list.Element)
+	c.lru.Init()
+	c.usedBytes = 0
+}
+
+// add caches automaton and evicts the least recently used automatons until the cache fits in its budget.
+// c.mu must be held.
+func (c *Cache) add(fingerprint Fingerprint, automaton *AhoCorasick) {
+	size := automaton.MemoryUsage()
+	if size > c.maxBytes {
+		return
+	}
+	for c.usedBytes+size > c.maxBytes {
+		c.remove(c.lru.Back())
+	}
+	c.entries[fingerprint] = c.lru.PushFront(&cacheEntry{fingerprint: fingerprint, automaton: automaton})
+	c.usedBytes += size
+}
+
+// remove removes element from the cache. c.mu must be held.
+func (c *Cache) remove(element *list.Element) {
+	entry := c.lru.Remove(element).(*cacheEntry)
+	delete(c.entries, entry.fingerprint)
+	c.usedBytes -= entry.automaton.MemoryUsage()
+}
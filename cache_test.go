@@ -0,0 +1,136 @@
+package ahocorasick
+
+import (
+	"errors"
+	"fmt"
+	. "github.com/smartystreets/goconvey/convey"
+	"sync"
+	"testing"
+)
+
+func TestCache(t *testing.T) {
+	Convey("GIVEN a cache with a generous budget", t, func() {
+		cache := NewCache(1 << 30)
+		patterns := []string{"foo", "bar"}
+
+		Convey("WHEN the same patterns and settings are requested twice", func() {
+			first, err := cache.Get(NewAhoCorasickBuilder(), patterns)
+			So(err, ShouldBeNil)
+			second, err := cache.Get(NewAhoCorasickBuilder(), []string{"foo", "bar"})
+			So(err, ShouldBeNil)
+
+			Convey("THEN the automaton is built once", func() {
+				So(second, ShouldPointTo, first)
+				So(cache.Len(), ShouldEqual, 1)
+				So(cache.Bytes(), ShouldEqual, first.MemoryUsage())
+			})
+		})
+
+		Convey("WHEN different settings are requested", func() {
+			first, _ := cache.Get(NewAhoCorasickBuilder(), patterns)
+			second, _ := cache.Get(NewAhoCorasickBuilder().SetMatchKind(MatchKindLeftMostFirst), patterns)
+
+			Convey("THEN different automatons are returned", func() {
+				So(second, ShouldNotPointTo, first)
+				So(cache.Len(), ShouldEqual, 2)
+			})
+		})
+
+		Convey("WHEN many goroutines request the same automaton concurrently", func() {
+			distinct := 0
+			results := make([]*AhoCorasick, 16)
+			var wg sync.WaitGroup
+			for i := range results {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					results[i], _ = cache.Get(NewAhoCorasickBuilder(), patterns)
+				}(i)
+			}
+			wg.Wait()
+			for _, result := range results {
+				if result != results[0] {
+					distinct++
+				}
+			}
+
+			Convey("THEN they all receive the same automaton", func() {
+				So(distinct, ShouldEqual, 0)
+				So(cache.Len(), ShouldEqual, 1)
+			})
+		})
+
+		Convey("WHEN building fails", func() {
+			_, err := cache.Get(NewAhoCorasickBuilder().SetMatchKind(MatchKind(0)), patterns)
+
+			Convey("THEN the error is returned and nothing is cached", func() {
+				So(err, ShouldNotBeNil)
+				So(cache.Len(), ShouldEqual, 0)
+			})
+		})
+
+		Convey("WHEN an in-flight build panics", func() {
+			fingerprint := FingerprintOf(NewAhoCorasickBuilder(), patterns)
+			call := &cacheCall{done: make(chan struct{})}
+			cache.inflight[fingerprint] = call
+			build := func() {
+				cache.build(fingerprint, call, func() (*AhoCorasick, error) {
+					panic("boom")
+				})
+			}
+
+			Convey("THEN the panic is propagated and the waiting callers are released with an error", func() {
+				So(build, ShouldPanicWith, "boom")
+				<-call.done
+				So(errors.Is(call.err, ErrBuildFailed), ShouldBeTrue)
+				So(call.err.Error(), ShouldContainSubstring, "boom")
+				So(cache.inflight, ShouldBeEmpty)
+				So(cache.Len(), ShouldEqual, 0)
+
+				automaton, err := cache.Get(NewAhoCorasickBuilder(), patterns)
+				So(err, ShouldBeNil)
+				So(automaton, ShouldNotBeNil)
+			})
+		})
+	})
+
+	Convey("GIVEN a cache with room for a single automaton", t, func() {
+		size := NewAhoCorasick([]string{"pattern_0"}).MemoryUsage()
+		cache := NewCache(size)
+
+		Convey("WHEN more automatons are requested", func() {
+			for i := 0; i < 3; i++ {
+				_, err := cache.Get(NewAhoCorasickBuilder(), []string{fmt.Sprintf("pattern_%d", i)})
+				So(err, ShouldBeNil)
+			}
+
+			Convey("THEN the least recently used ones are evicted", func() {
+				So(cache.Len(), ShouldEqual, 1)
+				So(cache.Bytes(), ShouldBeLessThanOrEqualTo, size)
+			})
+		})
+	})
+}
+
+func TestFingerprintOf(t *testing.T) {
+	Convey("GIVEN patterns whose concatenation is equal", t, func() {
+		first := FingerprintOf(NewAhoCorasickBuilder(), []string{"ab", "c"})
+		second := FingerprintOf(NewAhoCorasickBuilder(), []string{"a", "bc"})
+
+		Convey("THEN their fingerprints differ", func() {
+			So(first, ShouldNotEqual, second)
+		})
+	})
+}
+
+func TestFingerprintOfUTF8Aligned(t *testing.T) {
+	Convey("GIVEN builders that only differ by UTF8Aligned", t, func() {
+		patterns := []string{"é", "a"}
+		plain := FingerprintOf(NewAhoCorasickBuilder(), patterns)
+		aligned := FingerprintOf(NewAhoCorasickBuilder().SetUTF8Aligned(true), patterns)
+
+		Convey("THEN their fingerprints differ", func() {
+			So(aligned, ShouldNotEqual, plain)
+		})
+	})
+}
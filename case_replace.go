@@ -0,0 +1,103 @@
+package ahocorasick
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ReplaceAllPreservingCase replaces every match found by [AhoCorasick.FindAll] with the replacement of its pattern,
+// indexed by pattern ID, adapting the case of the replacement to the matched text using [MatchCase].
+//
+// This is meant for automatons built with [AhoCorasickBuilder.SetAsciiCaseInsensitive], so that, e.g., replacing
+// "colour" with "color" turns "Colour" into "Color" and "COLOUR" into "COLOR".
+// It panics if the number of replacements is not the number of patterns of this automaton.
+func (ac *AhoCorasick) ReplaceAllPreservingCase(input string, replacements []string) string {
+	if len(replacements) != ac.patternCount {
+		panic(fmt.Sprintf("ahocorasick: got %d replacements for %d patterns", len(replacements), ac.patternCount))
+	}
+	matches := ac.FindAll(input)
+	if len(matches) == 0 {
+		return input
+	}
+	var buf strings.Builder
+	buf.Grow(len(input))
+	last := uint(0)
+	for _, match := range matches {
+		buf.WriteString(input[last:match.Start])
+		buf.WriteString(MatchCase(input[match.Start:match.End], replacements[match.PatternIndex]))
+		last = match.End
+	}
+	buf.WriteString(input[last:])
+	return buf.String()
+}
+
+// MatchCase returns replacement with the case of its ASCII letters adapted to the case pattern of matched:
+//   - If matched has no upper case ASCII letter, replacement is returned in lower case.
+//   - If matched has more than one ASCII letter and all of them are upper case, replacement is returned in upper case.
+//   - If only the first ASCII letter of matched is upper case, only the first ASCII letter of replacement is.
+//   - Otherwise the case is mixed and applied by position: every ASCII letter of replacement takes the case of the
+//     byte at the same offset in matched if that is an ASCII letter, or of the last ASCII letter before it otherwise.
+//     Letters that have no ASCII letter at or before their offset in matched are returned in lower case.
+//
+// If matched has no ASCII letter at all, replacement is returned unchanged. Other bytes are never modified.
+func MatchCase(matched string, replacement string) string {
+	letters, upper := 0, 0
+	firstUpper := false
+	for i := 0; i < len(matched); i++ {
+		if isASCIILetter(matched[i]) {
+			if isASCIIUpper(matched[i]) {
+				firstUpper = firstUpper || letters == 0
+				upper++
+			}
+			letters++
+		}
+	}
+	switch {
+	case letters == 0:
+		return replacement
+	case upper == 0:
+		return mapASCIILetters(replacement, func(int) bool { return false })
+	case upper == letters && letters > 1:
+		return mapASCIILetters(replacement, func(int) bool { return true })
+	case upper == 1 && firstUpper:
+		first := true
+		return mapASCIILetters(replacement, func(int) bool {
+			isFirst := first
+			first = false
+			return isFirst
+		})
+	}
+	return mapASCIILetters(replacement, func(offset int) bool {
+		for i := offset; i >= 0; i-- {
+			if i < len(matched) && isASCIILetter(matched[i]) {
+				return isASCIIUpper(matched[i])
+			}
+		}
+		return false
+	})
+}
+
+// mapASCIILetters returns s with every ASCII letter converted to upper case if toUpper returns true for its offset,
+// and to lower case otherwise. toUpper is called for the letters in order.
+func mapASCIILetters(s string, toUpper func(offset int) bool) string {
+	result := []byte(s)
+	for i, c := range result {
+		if !isASCIILetter(c) {
+			continue
+		}
+		if toUpper(i) {
+			result[i] = c &^ 0x20
+		} else {
+			result[i] = c | 0x20
+		}
+	}
+	return string(result)
+}
+
+func isASCIILetter(c byte) bool {
+	return 'a' <= c|0x20 && c|0x20 <= 'z'
+}
+
+func isASCIIUpper(c byte) bool {
+	return 'A' <= c && c <= 'Z'
+}
@@ -0,0 +1,54 @@
+package ahocorasick
+
+import (
+	"fmt"
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+func ExampleAhoCorasick_ReplaceAllPreservingCase() {
+	automaton := NewAhoCorasickBuilder().SetAsciiCaseInsensitive(true).Build([]string{"colour", "grey"})
+	fmt.Println(automaton.ReplaceAllPreservingCase("Colour, COLOUR, colour and GreY", []string{"color", "gray"}))
+	// Output: Color, COLOR, color and GraY
+}
+
+func TestMatchCase(t *testing.T) {
+	Convey("GIVEN matched texts with various case patterns", t, func() {
+		cases := []struct {
+			matched, replacement, expected string
+		}{
+			{"colour", "Color", "color"},
+			{"COLOUR", "color", "COLOR"},
+			{"Colour", "color", "Color"},
+			{"C", "color", "Color"},
+			{"iPhone", "ipod", "iPod"},
+			{"GreY", "gray", "GraY"},
+			{"ab", "xYzW", "xyzw"},
+			{"aBc", "wxyz", "wXyz"},
+			{"1-2", "Foo", "Foo"},
+			{"1A", "new york", "New york"},
+			{"1aB", "xyz", "xyZ"},
+			{"ÉCOLE", "école", "éCOLE"},
+		}
+
+		Convey("THEN the case of the replacement is adapted to the matched text", func() {
+			for _, c := range cases {
+				So(MatchCase(c.matched, c.replacement), ShouldEqual, c.expected)
+			}
+		})
+	})
+}
+
+func TestReplaceAllPreservingCase(t *testing.T) {
+	Convey("GIVEN an ASCII case insensitive automaton", t, func() {
+		automaton := NewAhoCorasickBuilder().SetAsciiCaseInsensitive(true).Build([]string{"colour"})
+
+		Convey("THEN a haystack without matches is returned unchanged", func() {
+			So(automaton.ReplaceAllPreservingCase("color", []string{"color"}), ShouldEqual, "color")
+		})
+
+		Convey("THEN the number of replacements must match the number of patterns", func() {
+			So(func() { automaton.ReplaceAllPreservingCase("colour", nil) }, ShouldPanic)
+		})
+	})
+}
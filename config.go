@@ -0,0 +1,123 @@
+package ahocorasick
+
+// Config is a plain description of the settings of an [AhoCorasickBuilder].
+//
+// Unlike the builder itself, a Config can be compared, logged and (un)marshalled, for example from a JSON or YAML
+// configuration file. The enumerations are encoded using their textual names, e.g. "leftmost-longest" or "dfa".
+//
+// Start from [DefaultConfig] rather than the zero value when decoding a partial configuration, so that omitted
+// settings keep their default values.
+type Config struct {
+	// See [AhoCorasickBuilder.SetAsciiCaseInsensitive].
+	AsciiCaseInsensitive bool `json:"ascii_case_insensitive" yaml:"ascii_case_insensitive"`
+	// See [AhoCorasickBuilder.SetByteClasses].
+	ByteClasses bool `json:"byte_classes" yaml:"byte_classes"`
+	// See [AhoCorasickBuilder.SetDenseDepth]. nil means the default depth.
+	DenseDepth *uint `json:"dense_depth,omitempty" yaml:"dense_depth,omitempty"`
+	// See [AhoCorasickBuilder.SetKind]. nil means the kind is chosen automatically.
+	Kind *AhoCorasickKind `json:"kind,omitempty" yaml:"kind,omitempty"`
+	// See [AhoCorasickBuilder.SetMatchKind].
+	MatchKind MatchKind `json:"match_kind" yaml:"match_kind"`
+	// See [AhoCorasickBuilder.SetPrefilter].
+	Prefilter bool `json:"prefilter" yaml:"prefilter"`
+	// See [AhoCorasickBuilder.SetStartKind].
+	StartKind StartKind `json:"start_kind" yaml:"start_kind"`
+	// See [AhoCorasickBuilder.SetUTF8Aligned].
+	UTF8Aligned bool `json:"utf8_aligned" yaml:"utf8_aligned"`
+}
+
+// DefaultConfig returns the configuration used by [NewAhoCorasickBuilder].
+func DefaultConfig() Config {
+	return Config{
+		AsciiCaseInsensitive: false,
+		ByteClasses:          true,
+		DenseDepth:           nil,
+		Kind:                 nil,
+		MatchKind:            MatchKindStandard,
+		Prefilter:            true,
+		StartKind:            StartKindUnanchored,
+		UTF8Aligned:          false,
+	}
+}
+
+// NewAhoCorasickBuilderFromConfig creates a new builder using the settings described by config.
+//
+// The builder does not retain any reference to config.
+func NewAhoCorasickBuilderFromConfig(config Config) *AhoCorasickBuilder {
+	builder := &AhoCorasickBuilder{}
+	builder.applyConfig(config)
+	return builder
+}
+
+// applyConfig replaces the settings of b described by [Config], leaving the others (e.g. the observer) untouched.
+func (b *AhoCorasickBuilder) applyConfig(config Config) {
+	b.asciiCaseInsensitive = config.AsciiCaseInsensitive
+	b.byteClasses = config.ByteClasses
+	b.denseDepth = copyPtr(config.DenseDepth)
+	b.kind = copyPtr(config.Kind)
+	b.matchKind = config.MatchKind
+	b.prefilter = config.Prefilter
+	b.startKind = config.StartKind
+	b.utf8Aligned = config.UTF8Aligned
+}
+
+// Config returns a description of the current settings of this builder.
+//
+// The returned value does not share any memory with the builder, so modifying it does not affect the builder.
+func (b *AhoCorasickBuilder) Config() Config {
+	return Config{
+		AsciiCaseInsensitive: b.asciiCaseInsensitive,
+		ByteClasses:          b.byteClasses,
+		DenseDepth:           copyPtr(b.denseDepth),
+		Kind:                 copyPtr(b.kind),
+		MatchKind:            b.matchKind,
+		Prefilter:            b.prefilter,
+		StartKind:            b.startKind,
+		UTF8Aligned:          b.utf8Aligned,
+	}
+}
+
+// Validate reports an error if any of the settings in c is out of range.
+//
+// [AhoCorasickBuilder.TryBuild] and [New] validate their configuration using this method before building an automaton.
+func (c Config) Validate() error {
+	if _, err := c.MatchKind.MarshalText(); err != nil {
+		return err
+	}
+	if _, err := c.StartKind.MarshalText(); err != nil {
+		return err
+	}
+	if c.Kind != nil {
+		if _, err := c.Kind.MarshalText(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Equal reports whether c and other describe the same settings.
+func (c Config) Equal(other Config) bool {
+	return c.AsciiCaseInsensitive == other.AsciiCaseInsensitive &&
+		c.ByteClasses == other.ByteClasses &&
+		equalPtr(c.DenseDepth, other.DenseDepth) &&
+		equalPtr(c.Kind, other.Kind) &&
+		c.MatchKind == other.MatchKind &&
+		c.Prefilter == other.Prefilter &&
+		c.StartKind == other.StartKind &&
+		c.UTF8Aligned == other.UTF8Aligned
+}
+
+func equalPtr[T comparable](a, b *T) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func copyPtr[T any](p *T) *T {
+	if p == nil {
+		return nil
+	}
+	v := *p
+	return &v
+}
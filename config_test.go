@@ -0,0 +1,82 @@
+package ahocorasick
+
+import (
+	"encoding/json"
+	"fmt"
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+func ExampleConfig() {
+	config := DefaultConfig()
+	_ = json.Unmarshal([]byte(`{"match_kind": "leftmost-longest", "kind": "dfa"}`), &config)
+	builder := NewAhoCorasickBuilderFromConfig(config)
+	fmt.Println(builder.GetMatchKind(), *builder.GetKind(), builder.GetStartKind())
+	// Output: leftmost-longest dfa unanchored
+}
+
+func TestConfig(t *testing.T) {
+	Convey("GIVEN a builder with every setting changed from its default", t, func() {
+		denseDepth := uint(5)
+		kind := AhoCorasickKindContinuousNFA
+		builder := NewAhoCorasickBuilder().
+			SetAsciiCaseInsensitive(true).
+			SetByteClasses(false).
+			SetDenseDepth(&denseDepth).
+			SetKind(&kind).
+			SetMatchKind(MatchKindLeftMostFirst).
+			SetPrefilter(false).
+			SetStartKind(StartKindBoth).
+			SetUTF8Aligned(true)
+
+		Convey("WHEN its config is marshalled to JSON", func() {
+			data, err := json.Marshal(builder.Config())
+
+			Convey("THEN the enumerations are encoded by name", func() {
+				So(err, ShouldBeNil)
+				So(string(data), ShouldEqual, `{"ascii_case_insensitive":true,"byte_classes":false,"dense_depth":5,"kind":"contiguous-nfa","match_kind":"leftmost-first","prefilter":false,"start_kind":"both","utf8_aligned":true}`)
+			})
+
+			Convey("THEN unmarshalling it reconstructs an equal builder", func() {
+				var config Config
+				So(json.Unmarshal(data, &config), ShouldBeNil)
+				So(NewAhoCorasickBuilderFromConfig(config).Config().Equal(builder.Config()), ShouldBeTrue)
+			})
+		})
+
+		Convey("WHEN the builder is cloned", func() {
+			clone := builder.Clone()
+
+			Convey("THEN the clone has the same config", func() {
+				So(clone.Config().Equal(builder.Config()), ShouldBeTrue)
+			})
+
+			Convey("THEN changing the clone does not affect the original", func() {
+				clone.SetMatchKind(MatchKindStandard)
+				*clone.GetDenseDepth() = 10
+				So(builder.GetMatchKind(), ShouldEqual, MatchKindLeftMostFirst)
+				So(*builder.GetDenseDepth(), ShouldEqual, 5)
+			})
+		})
+	})
+
+	Convey("GIVEN an unknown kind name", t, func() {
+		var matchKind MatchKind
+		var startKind StartKind
+		var kind AhoCorasickKind
+
+		Convey("THEN unmarshalling it fails", func() {
+			So(matchKind.UnmarshalText([]byte("longest")), ShouldNotBeNil)
+			So(startKind.UnmarshalText([]byte("sometimes")), ShouldNotBeNil)
+			So(kind.UnmarshalText([]byte("nfa")), ShouldNotBeNil)
+		})
+	})
+
+	Convey("GIVEN an out of range kind", t, func() {
+		Convey("THEN it cannot be marshalled", func() {
+			_, err := MatchKind(0).MarshalText()
+			So(err, ShouldNotBeNil)
+			So(MatchKind(0).String(), ShouldEqual, "MatchKind(0)")
+		})
+	})
+}
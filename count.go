@@ -0,0 +1,100 @@
+package ahocorasick
+
+/*
+#include "./ahocorasick_rs.h"
+*/
+import "C"
+import (
+	"runtime"
+	"unsafe"
+)
+
+// Count returns the number of matches that [AhoCorasick.FindAll] would return, without allocating them.
+// Like FindAll, Count panics with an error wrapping [ErrUnsupportedSearch] if this automaton only supports anchored
+// searches.
+func (ac *AhoCorasick) Count(input string) int {
+	ac.requireUnanchored("Count")
+	total, _ := ac.count(ac, input, false, false)
+	return total
+}
+
+// CountByPattern returns, for every pattern, the number of its matches that [AhoCorasick.FindAll] would return,
+// indexed by pattern ID. Only the returned slice is allocated. See [AhoCorasick.Count] for the supported automatons.
+func (ac *AhoCorasick) CountByPattern(input string) []int {
+	ac.requireUnanchored("CountByPattern")
+	_, counts := ac.count(ac, input, false, true)
+	return counts
+}
+
+// CountOverlapping returns the number of matches that [AhoCorasick.FindOverlapping] would return, without
+// allocating them.
+//
+// Unlike FindOverlapping, this is supported by automatons using leftmost semantics, which count the matches of
+// the companion automaton described in [AhoCorasick.FindAllFiltered]. CountOverlapping panics with an error
+// wrapping [ErrUnsupportedSearch] if this automaton only supports anchored searches.
+func (ac *AhoCorasick) CountOverlapping(input string) int {
+	total, _ := ac.count(ac.unanchoredOverlapping("CountOverlapping"), input, true, false)
+	return total
+}
+
+// CountOverlappingByPattern returns, for every pattern, the number of its matches that
+// [AhoCorasick.FindOverlapping] would return, indexed by pattern ID. Only the returned slice is allocated.
+// See [AhoCorasick.CountOverlapping] for the supported automatons.
+func (ac *AhoCorasick) CountOverlappingByPattern(input string) []int {
+	_, counts := ac.count(ac.unanchoredOverlapping("CountOverlappingByPattern"), input, true, true)
+	return counts
+}
+
+// count counts the matches in input using automaton, which is either ac or its companion automaton,
+// and optionally the matches of every pattern.
+func (ac *AhoCorasick) count(automaton *AhoCorasick, input string, overlapping bool, byPattern bool) (int, []int) {
+	span := ac.beginSearch(SearchMethodCount)
+	if ac.config.UTF8Aligned {
+		// Whether a match is aligned is only known once it is found, so the matches are counted after the search.
+		var matches []Match
+		if overlapping {
+			matches = ac.alignedCandidates(input, 0, len(input), alignedIn(input))
+		} else {
+			matches = ac.findAllIn(input, 0, len(input))
+		}
+		span.end(len(input), len(matches), false)
+		if !byPattern {
+			return len(matches), nil
+		}
+		return len(matches), patternCounts(matches, ac.patternCount)
+	}
+	cText := (*C.char)(unsafe.Pointer(unsafe.StringData(input)))
+	cOverlapping := C.int(0)
+	if overlapping {
+		cOverlapping = 1
+	}
+	var cCounts []C.size_t
+	if byPattern {
+		cCounts = make([]C.size_t, ac.patternCount)
+	}
+	cErr := C.int(0)
+	total := int(C.count_matches(automaton.native(), cText, C.size_t(len(input)), cOverlapping, unsafe.SliceData(cCounts), &cErr))
+	runtime.KeepAlive(cText)
+	runtime.KeepAlive(input)
+	runtime.KeepAlive(cCounts)
+	runtime.KeepAlive(automaton)
+	checkNativeSearch(cErr)
+	span.end(len(input), total, false)
+	if !byPattern {
+		return total, nil
+	}
+	counts := make([]int, len(cCounts))
+	for i, count := range cCounts {
+		counts[i] = int(count)
+	}
+	return total, counts
+}
+
+// patternCounts returns the number of matches of every pattern, indexed by pattern ID.
+func patternCounts(matches []Match, patternCount int) []int {
+	counts := make([]int, patternCount)
+	for _, match := range matches {
+		counts[match.PatternIndex]++
+	}
+	return counts
+}
@@ -0,0 +1,58 @@
+package ahocorasick
+
+import (
+	"fmt"
+	. "github.com/smartystreets/goconvey/convey"
+	"math/rand"
+	"testing"
+)
+
+func ExampleAhoCorasick_CountByPattern() {
+	automaton := NewAhoCorasick([]string{"append", "appendage", "app"})
+	haystack := "append the appendage"
+	fmt.Println(automaton.Count(haystack), automaton.CountByPattern(haystack))
+	fmt.Println(automaton.CountOverlapping(haystack), automaton.CountOverlappingByPattern(haystack))
+	// Output:
+	// 2 [0 0 2]
+	// 5 [2 1 2]
+}
+
+// countByPattern counts the matches of every pattern.
+func countByPattern(matches []Match, patternCount int) []int {
+	counts := make([]int, patternCount)
+	for _, match := range matches {
+		counts[match.PatternIndex]++
+	}
+	return counts
+}
+
+func TestCount(t *testing.T) {
+	Convey("GIVEN random patterns and haystacks", t, func() {
+		random := rand.New(rand.NewSource(1))
+
+		Convey("THEN the counts agree with the matches found", func() {
+			for _, matchKind := range []MatchKind{MatchKindStandard, MatchKindLeftMostFirst, MatchKindLeftMostLongest} {
+				for i := 0; i < 200; i++ {
+					patterns := randomPatterns(random, 1+random.Intn(6), 3)
+					haystack := randomPatterns(random, 1, 30)[0]
+					automaton := NewAhoCorasickBuilder().SetMatchKind(matchKind).Build(patterns)
+					matches := automaton.FindAll(haystack)
+					overlapping := NewAhoCorasick(patterns).FindOverlapping(haystack)
+					So(automaton.Count(haystack), ShouldEqual, len(matches))
+					So(automaton.CountByPattern(haystack), ShouldResemble, countByPattern(matches, len(patterns)))
+					So(automaton.CountOverlapping(haystack), ShouldEqual, len(overlapping))
+					So(automaton.CountOverlappingByPattern(haystack), ShouldResemble, countByPattern(overlapping, len(patterns)))
+				}
+			}
+		})
+	})
+
+	Convey("GIVEN an automaton without patterns", t, func() {
+		automaton := NewAhoCorasick(nil)
+
+		Convey("THEN there are no matches to count", func() {
+			So(automaton.Count("foo"), ShouldEqual, 0)
+			So(automaton.CountByPattern("foo"), ShouldBeEmpty)
+		})
+	})
+}
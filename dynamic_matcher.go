@@ -0,0 +1,524 @@
+package ahocorasick
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// DefaultCompactionThreshold is the compaction threshold of a new [DynamicMatcher].
+const DefaultCompactionThreshold = 1024
+
+// DynamicMatcher is a mutable set of patterns that supports adding and removing patterns without rebuilding
+// an automaton for the whole set every time.
+//
+// Patterns are split between a large base automaton and a small delta automaton holding the patterns added since
+// the base was built. Removed patterns are filtered out of the base automaton's matches. Once the delta and the
+// removed patterns grow past the compaction threshold, a new base automaton is built in the background.
+//
+// When the delta automaton does not match and no match of the base automaton belongs to a removed pattern,
+// a search costs the same as a search of the base automaton. Otherwise, the matches are selected from all
+// overlapping matches of both automatons, like [AhoCorasick.FindAllFiltered] does.
+//
+// Every pattern is identified by a stable ID, returned by [DynamicMatcher.Add], which is reported as the
+// PatternIndex of its matches. IDs are assigned in insertion order starting at 0 and are never reused, so for
+// [MatchKindLeftMostFirst] the priority of a pattern is determined by its ID, just like for an [AhoCorasick].
+// Matches are selected from the candidates of both automatons according to the match kind of the builder
+// the matcher was created with.
+//
+// Replaced automatons are closed as soon as the searches still using them have finished, like the automatons of
+// an [AtomicAutomaton], and [DynamicMatcher.Close] releases the current ones.
+//
+// A DynamicMatcher is safe for concurrent use. Searches never block on updates.
+type DynamicMatcher struct {
+	baseBuilder  *AhoCorasickBuilder
+	deltaBuilder *AhoCorasickBuilder
+	matchKind    MatchKind
+	name         string
+	observer     Observer
+
+	mu         sync.Mutex
+	closed     bool
+	nextID     uint
+	patterns   map[uint]string
+	threshold  int
+	compacting bool
+	state      atomic.Pointer[dynamicState]
+
+	compactMu sync.Mutex
+}
+
+// dynamicState is an immutable snapshot of the automatons of a [DynamicMatcher].
+//
+// A state holds one reference to each of its segments, which may be shared with the previous and next states.
+// The state itself counts one reference for being the current state of the matcher, and one for every search
+// in progress; it releases its segments when the count drops to zero.
+type dynamicState struct {
+	base  dynamicSegment
+	delta dynamicSegment
+	// The IDs of the patterns removed since the base automaton was built.
+	removed map[uint]struct{}
+	refs    atomic.Int64
+}
+
+func newDynamicState(base dynamicSegment, delta dynamicSegment, removed map[uint]struct{}) *dynamicState {
+	result := &dynamicState{base: base, delta: delta, removed: removed}
+	result.refs.Store(1)
+	return result
+}
+
+func (s *dynamicState) release() {
+	if s.refs.Add(-1) == 0 {
+		s.base.release()
+		s.delta.release()
+	}
+}
+
+// dynamicSegment is an automaton built from a subset of the patterns of a [DynamicMatcher].
+type dynamicSegment struct {
+	// nil if the segment has no patterns.
+	ref *refCountedAutomaton
+	// The stable ID of each pattern of the automaton, indexed by its pattern index.
+	ids []uint
+}
+
+// retain takes a reference to the automaton of the segment, for a state sharing it.
+func (s dynamicSegment) retain() dynamicSegment {
+	if s.ref != nil {
+		s.ref.refs.Add(1)
+	}
+	return s
+}
+
+func (s dynamicSegment) release() {
+	if s.ref != nil {
+		s.ref.release()
+	}
+}
+
+// NewDynamicMatcher creates a [DynamicMatcher] whose base automaton holds patterns, which get the IDs 0, 1 and so on.
+//
+// The automatons are built using the settings of builder, except that the delta automaton always uses
+// [MatchKindStandard], since its matches are only used as candidates. The name and observer of builder apply to
+// the matcher rather than to its internal automatons: the name labels the runtime/trace regions of its searches,
+// the internal automatons are not registered (see [RegisteredAutomata]), and the observer receives a single event
+// per search of the matcher. An error is returned if builder does not support unanchored searches or if the base
+// automaton could not be built.
+func NewDynamicMatcher(builder *AhoCorasickBuilder, patterns []string) (*DynamicMatcher, error) {
+	if builder.startKind == StartKindAnchored {
+		return nil, fmt.Errorf("%w: a DynamicMatcher requires unanchored searches to be supported", ErrUnsupportedSearch)
+	}
+	if err := builder.Config().Validate(); err != nil {
+		return nil, err
+	}
+	segmentBuilder := builder.Clone().SetName("").SetObserver(nil)
+	m := &DynamicMatcher{
+		baseBuilder:  segmentBuilder,
+		deltaBuilder: segmentBuilder.Clone().SetMatchKind(MatchKindStandard),
+		matchKind:    builder.matchKind,
+		name:         builder.name,
+		observer:     builder.observer,
+		nextID:       uint(len(patterns)),
+		patterns:     make(map[uint]string, len(patterns)),
+		threshold:    DefaultCompactionThreshold,
+	}
+	ids := make([]uint, len(patterns))
+	for i, pattern := range patterns {
+		ids[i] = uint(i)
+		m.patterns[uint(i)] = pattern
+	}
+	base, err := buildSegment(m.baseBuilder, ids, patterns)
+	if err != nil {
+		return nil, err
+	}
+	m.state.Store(newDynamicState(base, dynamicSegment{}, nil))
+	return m, nil
+}
+
+// SetCompactionThreshold sets the number of added and removed patterns after which the base automaton is rebuilt
+// in the background. The default is [DefaultCompactionThreshold].
+func (m *DynamicMatcher) SetCompactionThreshold(threshold int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.threshold = threshold
+	m.maybeCompact()
+}
+
+// Add adds a pattern and returns its ID.
+//
+// The delta automaton is rebuilt before Add returns, so the pattern is matched by every search that starts afterwards.
+// An error is returned if the delta automaton could not be built, in which case the pattern is not added.
+func (m *DynamicMatcher) Add(pattern string) (uint, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.closed {
+		return 0, ErrClosed
+	}
+	state := m.state.Load()
+	id := m.nextID
+	m.patterns[id] = pattern
+	ids := append(append([]uint(nil), state.delta.ids...), id)
+	delta, err := buildSegment(m.deltaBuilder, ids, m.patternsOf(ids))
+	if err != nil {
+		delete(m.patterns, id)
+		return 0, err
+	}
+	m.nextID++
+	m.replaceState(newDynamicState(state.base.retain(), delta, state.removed))
+	m.maybeCompact()
+	return id, nil
+}
+
+// Remove removes the pattern with the given ID and reports whether it was present.
+//
+// Like [DynamicMatcher.Add], the removal is visible to every search that starts after Remove returns.
+func (m *DynamicMatcher) Remove(id uint) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.closed {
+		return false, ErrClosed
+	}
+	if _, ok := m.patterns[id]; !ok {
+		return false, nil
+	}
+	state := m.state.Load()
+	var next *dynamicState
+	if index := indexOf(state.delta.ids, id); index >= 0 {
+		ids := append(append([]uint(nil), state.delta.ids[:index]...), state.delta.ids[index+1:]...)
+		delta, err := buildSegment(m.deltaBuilder, ids, m.patternsOf(ids))
+		if err != nil {
+			return false, err
+		}
+		next = newDynamicState(state.base.retain(), delta, state.removed)
+	} else {
+		removed := make(map[uint]struct{}, len(state.removed)+1)
+		for previous := range state.removed {
+			removed[previous] = struct{}{}
+		}
+		removed[id] = struct{}{}
+		next = newDynamicState(state.base.retain(), state.delta.retain(), removed)
+	}
+	delete(m.patterns, id)
+	m.replaceState(next)
+	m.maybeCompact()
+	return true, nil
+}
+
+// Pattern returns the pattern with the given ID, if it has not been removed.
+func (m *DynamicMatcher) Pattern(id uint) (string, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	pattern, ok := m.patterns[id]
+	return pattern, ok
+}
+
+// Len returns the number of patterns.
+func (m *DynamicMatcher) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.patterns)
+}
+
+// Compact rebuilds the base automaton from all patterns, leaving the delta automaton empty unless patterns are
+// added concurrently. It blocks until the new base automaton is in use.
+func (m *DynamicMatcher) Compact() error {
+	m.compactMu.Lock()
+	defer m.compactMu.Unlock()
+
+	m.mu.Lock()
+	if m.closed {
+		m.mu.Unlock()
+		return ErrClosed
+	}
+	ids := make([]uint, 0, len(m.patterns))
+	for id := range m.patterns {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return ids[i] < ids[j]
+	})
+	patterns := m.patternsOf(ids)
+	m.mu.Unlock()
+	base, err := buildSegment(m.baseBuilder, ids, patterns)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.closed {
+		base.release()
+		return ErrClosed
+	}
+	var removed map[uint]struct{}
+	var deltaIDs []uint
+	for id := range m.patterns {
+		if !containsSorted(base.ids, id) {
+			deltaIDs = append(deltaIDs, id)
+		}
+	}
+	for _, id := range base.ids {
+		if _, ok := m.patterns[id]; !ok {
+			if removed == nil {
+				removed = make(map[uint]struct{})
+			}
+			removed[id] = struct{}{}
+		}
+	}
+	sort.Slice(deltaIDs, func(i, j int) bool {
+		return deltaIDs[i] < deltaIDs[j]
+	})
+	delta, err := buildSegment(m.deltaBuilder, deltaIDs, m.patternsOf(deltaIDs))
+	if err != nil {
+		base.release()
+		return err
+	}
+	m.replaceState(newDynamicState(base, delta, removed))
+	return nil
+}
+
+// Close releases the automatons of this matcher once the searches using them have finished.
+//
+// Afterwards, [DynamicMatcher.Add], [DynamicMatcher.Remove] and [DynamicMatcher.Compact] return [ErrClosed] and
+// searches panic with [ErrClosed]. Calling Close more than once has no effect. Close always returns nil.
+func (m *DynamicMatcher) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.closed {
+		return nil
+	}
+	m.closed = true
+	m.state.Swap(nil).release()
+	return nil
+}
+
+// replaceState makes next the current state and releases the previous one. m.mu must be held.
+func (m *DynamicMatcher) replaceState(next *dynamicState) {
+	m.state.Swap(next).release()
+}
+
+// acquire returns the current state after taking a reference to it.
+// It panics with [ErrClosed] if the matcher was closed.
+func (m *DynamicMatcher) acquire() *dynamicState {
+	for {
+		current := m.state.Load()
+		if current == nil {
+			panic(ErrClosed)
+		}
+		current.refs.Add(1)
+		if m.state.Load() == current {
+			return current
+		}
+		// The state was replaced between loading it and taking the reference, so its automatons may be closed.
+		current.release()
+	}
+}
+
+// maybeCompact starts a background compaction if the threshold was reached. m.mu must be held.
+func (m *DynamicMatcher) maybeCompact() {
+	if m.closed || m.compacting {
+		return
+	}
+	state := m.state.Load()
+	pending := len(state.delta.ids) + len(state.removed)
+	if pending == 0 || pending < m.threshold {
+		return
+	}
+	m.compacting = true
+	go func() {
+		err := m.Compact()
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		m.compacting = false
+		// Updates made during the compaction are left in the delta automaton, so they may have reached the threshold
+		// again. A failed compaction leaves the current automatons in place; it is retried after the next update.
+		if err == nil {
+			m.maybeCompact()
+		}
+	}()
+}
+
+// patternsOf returns the patterns with the given IDs. m.mu must be held.
+func (m *DynamicMatcher) patternsOf(ids []uint) []string {
+	patterns := make([]string, len(ids))
+	for i, id := range ids {
+		patterns[i] = m.patterns[id]
+	}
+	return patterns
+}
+
+// buildSegment builds an automaton for the given patterns, whose IDs are ids, using builder.
+func buildSegment(builder *AhoCorasickBuilder, ids []uint, patterns []string) (dynamicSegment, error) {
+	if len(ids) == 0 {
+		return dynamicSegment{}, nil
+	}
+	automaton, err := builder.TryBuild(patterns)
+	if err != nil {
+		return dynamicSegment{}, err
+	}
+	return dynamicSegment{ref: newRefCountedAutomaton(automaton), ids: ids}, nil
+}
+
+// find returns at most limit non-overlapping matches in haystack selected according to matchKind, or all of them
+// if limit is negative.
+func (s *dynamicState) find(haystack string, matchKind MatchKind, limit int) []Match {
+	if !s.delta.isMatch(haystack) {
+		var matches []Match
+		if limit == 1 {
+			matches = s.base.findFirst(haystack)
+		} else {
+			matches = s.base.findAll(haystack)
+		}
+		if len(s.removed) == 0 || !s.anyRemoved(matches) {
+			return matches
+		}
+	}
+	return selectNonOverlapping(s.candidates(haystack), matchKind, limit)
+}
+
+// isMatch reports whether any pattern that was not removed matches haystack.
+func (s *dynamicState) isMatch(haystack string) bool {
+	if s.delta.isMatch(haystack) {
+		return true
+	}
+	if !s.base.isMatch(haystack) {
+		return false
+	}
+	return len(s.removed) == 0 || len(s.base.appendCandidates(nil, haystack, s.removed)) > 0
+}
+
+// anyRemoved reports whether any of matches belongs to a removed pattern.
+func (s *dynamicState) anyRemoved(matches []Match) bool {
+	for _, match := range matches {
+		if _, ok := s.removed[match.PatternIndex]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// candidates returns every match of every pattern in haystack, with pattern IDs as pattern indexes.
+func (s *dynamicState) candidates(haystack string) []Match {
+	candidates := s.base.appendCandidates(nil, haystack, s.removed)
+	return s.delta.appendCandidates(candidates, haystack, nil)
+}
+
+// appendCandidates appends the overlapping matches of the segment, except those of removed patterns.
+// They are found by the companion automaton described in [AhoCorasick.FindAllFiltered] if the segment does not use
+// [MatchKindStandard].
+func (s dynamicSegment) appendCandidates(candidates []Match, haystack string, removed map[uint]struct{}) []Match {
+	if s.ref == nil {
+		return candidates
+	}
+	matches := s.ref.automaton.overlapping().FindOverlapping(haystack)
+	if s.ref.automaton.config.UTF8Aligned {
+		// The companion automaton keeps the matches that split characters.
+		matches = filterAligned(matches, alignedIn(haystack))
+	}
+	for _, match := range matches {
+		match.PatternIndex = s.ids[match.PatternIndex]
+		if _, ok := removed[match.PatternIndex]; !ok {
+			candidates = append(candidates, match)
+		}
+	}
+	return candidates
+}
+
+// findAll returns the matches of the segment, with pattern IDs as pattern indexes. Since the IDs of a segment are
+// sorted, the priorities of its patterns are the same as those of their IDs.
+func (s dynamicSegment) findAll(haystack string) []Match {
+	if s.ref == nil {
+		return nil
+	}
+	return s.withIDs(s.ref.automaton.FindAll(haystack))
+}
+
+// findFirst returns the first match of the segment, if any, with its pattern ID as pattern index.
+func (s dynamicSegment) findFirst(haystack string) []Match {
+	if s.ref == nil {
+		return nil
+	}
+	match := s.ref.automaton.FindFirst(haystack)
+	if match == nil {
+		return nil
+	}
+	return s.withIDs([]Match{*match})
+}
+
+func (s dynamicSegment) isMatch(haystack string) bool {
+	return s.ref != nil && s.ref.automaton.IsMatch(haystack)
+}
+
+// withIDs replaces the pattern indexes of matches with pattern IDs, in place.
+func (s dynamicSegment) withIDs(matches []Match) []Match {
+	for i := range matches {
+		matches[i].PatternIndex = s.ids[matches[i].PatternIndex]
+	}
+	return matches
+}
+
+// FindAll returns the non-overlapping matches in haystack, selected according to the match kind of the matcher.
+func (m *DynamicMatcher) FindAll(haystack string) []Match {
+	span := beginSearch(nil, m.name, m.observer, SearchMethodFindAll)
+	state := m.acquire()
+	defer state.release()
+	result := state.find(haystack, m.matchKind, -1)
+	span.end(len(haystack), len(result), false)
+	return result
+}
+
+// FindFirst returns the first match in haystack according to the match kind of the matcher, or nil.
+func (m *DynamicMatcher) FindFirst(haystack string) *Match {
+	span := beginSearch(nil, m.name, m.observer, SearchMethodFindFirst)
+	state := m.acquire()
+	defer state.release()
+	matches := state.find(haystack, m.matchKind, 1)
+	if len(matches) == 0 {
+		span.end(len(haystack), 0, false)
+		return nil
+	}
+	span.end(len(haystack), 1, true)
+	return &matches[0]
+}
+
+// FindOverlapping returns every match of every pattern in haystack, ordered by their ending position.
+func (m *DynamicMatcher) FindOverlapping(haystack string) []Match {
+	span := beginSearch(nil, m.name, m.observer, SearchMethodFindOverlapping)
+	state := m.acquire()
+	defer state.release()
+	candidates := state.candidates(haystack)
+	sortCandidates(candidates, MatchKindStandard)
+	span.end(len(haystack), len(candidates), false)
+	return candidates
+}
+
+// IsMatch returns true if and only if any pattern matches haystack.
+func (m *DynamicMatcher) IsMatch(haystack string) bool {
+	span := beginSearch(nil, m.name, m.observer, SearchMethodIsMatch)
+	state := m.acquire()
+	defer state.release()
+	found := state.isMatch(haystack)
+	matchCount := 0
+	if found {
+		matchCount = 1
+	}
+	span.end(len(haystack), matchCount, found)
+	return found
+}
+
+func containsSorted(ids []uint, id uint) bool {
+	i := sort.Search(len(ids), func(i int) bool {
+		return ids[i] >= id
+	})
+	return i < len(ids) && ids[i] == id
+}
+
+func indexOf(ids []uint, id uint) int {
+	for i, candidate := range ids {
+		if candidate == id {
+			return i
+		}
+	}
+	return -1
+}
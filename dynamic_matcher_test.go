@@ -0,0 +1,305 @@
+package ahocorasick
+
+import (
+	"fmt"
+	. "github.com/smartystreets/goconvey/convey"
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func ExampleDynamicMatcher() {
+	matcher, err := NewDynamicMatcher(NewAhoCorasickBuilder().SetMatchKind(MatchKindLeftMostLongest), []string{"foo", "bar"})
+	if err != nil {
+		panic(err)
+	}
+	id, _ := matcher.Add("foobar")
+	_, _ = matcher.Remove(1)
+	fmt.Println(id, matcher.FindAll("foobar bar foo"))
+	// Output: 2 [{6 2 0} {14 0 11}]
+}
+
+// expectedDynamicMatches returns the matches of an automaton built by builder from the live patterns of matcher,
+// with pattern indexes translated to IDs.
+func expectedDynamicMatches(matcher *DynamicMatcher, builder *AhoCorasickBuilder, haystack string) []Match {
+	var ids []uint
+	for id := uint(0); id < matcher.nextID; id++ {
+		if _, ok := matcher.Pattern(id); ok {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return ids[i] < ids[j]
+	})
+	patterns := make([]string, len(ids))
+	for i, id := range ids {
+		patterns[i], _ = matcher.Pattern(id)
+	}
+	matches := builder.Build(patterns).FindAll(haystack)
+	for i := range matches {
+		matches[i].PatternIndex = ids[matches[i].PatternIndex]
+	}
+	return matches
+}
+
+func TestDynamicMatcher(t *testing.T) {
+	for _, matchKind := range []MatchKind{MatchKindStandard, MatchKindLeftMostFirst, MatchKindLeftMostLongest} {
+		Convey(fmt.Sprintf("GIVEN a dynamic matcher using %v semantics", matchKind), t, func() {
+			random := rand.New(rand.NewSource(1))
+			matcher, err := NewDynamicMatcher(NewAhoCorasickBuilder().SetMatchKind(matchKind), []string{"ab", "bc", "abc"})
+			So(err, ShouldBeNil)
+			matcher.SetCompactionThreshold(5)
+
+			Convey("WHEN patterns are added and removed", func() {
+				for i := 0; i < 50; i++ {
+					if random.Intn(3) == 0 {
+						_, err = matcher.Remove(uint(random.Intn(int(matcher.nextID))))
+					} else {
+						_, err = matcher.Add(randomPatterns(random, 1, 3)[0] + "a")
+					}
+					So(err, ShouldBeNil)
+					if i%10 == 0 {
+						So(matcher.Compact(), ShouldBeNil)
+					}
+
+					Convey(fmt.Sprintf("THEN the matches are those of a freshly built automaton (step %d)", i), func() {
+						for j := 0; j < 10; j++ {
+							haystack := randomPatterns(random, 1, 20)[0]
+							expected := expectedDynamicMatches(matcher, NewAhoCorasickBuilder().SetMatchKind(matchKind), haystack)
+							actual := matcher.FindAll(haystack)
+							if len(expected) == 0 {
+								So(actual, ShouldBeEmpty)
+								So(matcher.IsMatch(haystack), ShouldBeFalse)
+							} else {
+								So(actual, ShouldResemble, expected)
+								So(matcher.FindFirst(haystack), ShouldResemble, &expected[0])
+								So(matcher.IsMatch(haystack), ShouldBeTrue)
+							}
+						}
+					})
+				}
+			})
+		})
+	}
+
+	Convey("GIVEN a leftmost-first dynamic matcher without added or removed patterns", t, func() {
+		matcher, err := NewDynamicMatcher(NewAhoCorasickBuilder().SetMatchKind(MatchKindLeftMostFirst), []string{"Samwise", "Sam"})
+		So(err, ShouldBeNil)
+
+		Convey("THEN searches use the base automaton only", func() {
+			So(matcher.FindAll("Samwise"), ShouldResemble, []Match{{End: 7, PatternIndex: 0, Start: 0}})
+			So(matcher.FindFirst("Samwise"), ShouldResemble, &Match{End: 7, PatternIndex: 0, Start: 0})
+			So(matcher.state.Load().base.ref.automaton.companion, ShouldBeNil)
+		})
+	})
+
+	Convey("GIVEN a builder supporting only anchored searches", t, func() {
+		builder := NewAhoCorasickBuilder().SetStartKind(StartKindAnchored)
+
+		Convey("THEN a dynamic matcher cannot be created", func() {
+			_, err := NewDynamicMatcher(builder, []string{"foo"})
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
+
+func TestDynamicMatcherConcurrentCompaction(t *testing.T) {
+	Convey("GIVEN a dynamic matcher with a low compaction threshold", t, func() {
+		matcher, err := NewDynamicMatcher(NewAhoCorasickBuilder(), []string{"foo"})
+		So(err, ShouldBeNil)
+		defer matcher.Close()
+		matcher.SetCompactionThreshold(4)
+
+		Convey("WHEN patterns are added and removed concurrently with background compactions", func() {
+			var wg sync.WaitGroup
+			errs := make(chan error, 8)
+			for i := 0; i < cap(errs); i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					for j := 0; j < 50; j++ {
+						id, err := matcher.Add(fmt.Sprintf("pattern_%d_%d", i, j))
+						if err == nil && j%2 == 0 {
+							_, err = matcher.Remove(id)
+						}
+						if err != nil {
+							errs <- err
+							return
+						}
+					}
+				}(i)
+			}
+			wg.Wait()
+			close(errs)
+			for compacting := true; compacting; {
+				time.Sleep(time.Millisecond)
+				matcher.mu.Lock()
+				compacting = matcher.compacting
+				matcher.mu.Unlock()
+			}
+
+			Convey("THEN the pending updates end up below the threshold", func() {
+				So(<-errs, ShouldBeNil)
+				state := matcher.state.Load()
+				So(len(state.delta.ids)+len(state.removed), ShouldBeLessThan, 4)
+				So(matcher.Len(), ShouldEqual, 1+cap(errs)*25)
+				So(matcher.FindAll("pattern_3_7"), ShouldResemble, expectedDynamicMatches(matcher, NewAhoCorasickBuilder(), "pattern_3_7"))
+			})
+		})
+	})
+}
+
+// liveAutomataCreatedBy returns the number of live tracked automatons created by the given test function.
+func TestDynamicMatcherUTF8Aligned(t *testing.T) {
+	for _, matchKind := range []MatchKind{MatchKindStandard, MatchKindLeftMostFirst, MatchKindLeftMostLongest} {
+		Convey(fmt.Sprintf("GIVEN a dynamic matcher using %v semantics whose matches must be aligned on characters", matchKind), t, func() {
+			random := rand.New(rand.NewSource(1))
+			builder := NewAhoCorasickBuilder().SetMatchKind(matchKind).SetUTF8Aligned(true)
+			matcher, err := NewDynamicMatcher(builder, randomUTF8Strings(random, 3, 2))
+			So(err, ShouldBeNil)
+			matcher.SetCompactionThreshold(5)
+
+			Convey("WHEN patterns are added and removed", func() {
+				for i := 0; i < 50; i++ {
+					if random.Intn(3) == 0 {
+						_, err = matcher.Remove(uint(random.Intn(int(matcher.nextID))))
+					} else {
+						_, err = matcher.Add(randomUTF8Strings(random, 1, 2)[0])
+					}
+					So(err, ShouldBeNil)
+					if i%10 == 0 {
+						So(matcher.Compact(), ShouldBeNil)
+					}
+
+					Convey(fmt.Sprintf("THEN the matches are those of a freshly built aligned automaton (step %d)", i), func() {
+						for j := 0; j < 10; j++ {
+							haystack := randomUTF8Strings(random, 1, 12)[0]
+							expected := expectedDynamicMatches(matcher, builder.Clone(), haystack)
+							if len(expected) == 0 {
+								So(matcher.FindAll(haystack), ShouldBeEmpty)
+								So(matcher.IsMatch(haystack), ShouldBeFalse)
+							} else {
+								So(matcher.FindAll(haystack), ShouldResemble, expected)
+								So(matcher.FindFirst(haystack), ShouldResemble, &expected[0])
+								So(matcher.IsMatch(haystack), ShouldBeTrue)
+							}
+						}
+					})
+				}
+			})
+		})
+	}
+
+	Convey("GIVEN a leftmost-first aligned dynamic matcher whose removed pattern hides a misaligned match", t, func() {
+		matcher, err := NewDynamicMatcher(NewAhoCorasickBuilder().SetMatchKind(MatchKindLeftMostFirst).SetUTF8Aligned(true), []string{"\xa9x", "x"})
+		So(err, ShouldBeNil)
+		_, err = matcher.Add("zz")
+		So(err, ShouldBeNil)
+		_, err = matcher.Remove(1)
+		So(err, ShouldBeNil)
+
+		Convey("THEN the match splitting a character is not reported", func() {
+			So(matcher.FindAll("éx"), ShouldBeEmpty)
+			So(matcher.IsMatch("éx"), ShouldBeFalse)
+		})
+	})
+}
+
+func liveAutomataCreatedBy(function string) int {
+	count := 0
+	for _, tracked := range LiveAutomata().Tracked {
+		if strings.Contains(tracked.Stack, function) {
+			count++
+		}
+	}
+	return count
+}
+
+func TestDynamicMatcherClose(t *testing.T) {
+	Convey("GIVEN a dynamic matcher created while leak tracking is enabled", t, func() {
+		SetLeakTracking(true)
+		Reset(func() {
+			SetLeakTracking(false)
+		})
+		matcher, err := NewDynamicMatcher(NewAhoCorasickBuilder(), []string{"foo", "bar"})
+		So(err, ShouldBeNil)
+		Reset(func() {
+			matcher.Close()
+		})
+
+		Convey("WHEN patterns are added", func() {
+			for i := 0; i < 5; i++ {
+				_, err = matcher.Add(fmt.Sprintf("baz%d", i))
+				So(err, ShouldBeNil)
+			}
+
+			Convey("THEN the replaced delta automatons are closed", func() {
+				So(liveAutomataCreatedBy("TestDynamicMatcherClose"), ShouldEqual, 2)
+			})
+
+			Convey("THEN closing the matcher releases its automatons", func() {
+				So(matcher.Close(), ShouldBeNil)
+				So(matcher.Close(), ShouldBeNil)
+				So(liveAutomataCreatedBy("TestDynamicMatcherClose"), ShouldEqual, 0)
+				_, err = matcher.Add("qux")
+				So(err, ShouldEqual, ErrClosed)
+				So(func() { matcher.IsMatch("foo") }, ShouldPanicWith, ErrClosed)
+			})
+		})
+
+		Convey("WHEN it is compacted", func() {
+			_, err = matcher.Remove(0)
+			So(err, ShouldBeNil)
+			_, err = matcher.Add("baz")
+			So(err, ShouldBeNil)
+			So(matcher.Compact(), ShouldBeNil)
+
+			Convey("THEN only the new base automaton is live", func() {
+				So(liveAutomataCreatedBy("TestDynamicMatcherClose"), ShouldEqual, 1)
+				So(matcher.FindAll("foo bar baz"), ShouldResemble, []Match{{End: 7, PatternIndex: 1, Start: 4}, {End: 11, PatternIndex: 2, Start: 8}})
+			})
+		})
+	})
+}
+
+func TestDynamicMatcherObserver(t *testing.T) {
+	Convey("GIVEN a dynamic matcher created from a named and observed builder", t, func() {
+		var events []SearchEvent
+		builder := NewAhoCorasickBuilder().
+			SetMatchKind(MatchKindLeftMostFirst).
+			SetName("TestDynamicMatcherObserver").
+			SetObserver(ObserverFunc(func(event SearchEvent) {
+				events = append(events, event)
+			}))
+		matcher, err := NewDynamicMatcher(builder, []string{"foo", "bar"})
+		So(err, ShouldBeNil)
+		Reset(func() {
+			matcher.Close()
+		})
+		for i := 0; i < 3; i++ {
+			_, err = matcher.Add(fmt.Sprintf("baz%d", i))
+			So(err, ShouldBeNil)
+		}
+
+		Convey("WHEN it searches using both of its automatons", func() {
+			matches := matcher.FindAll("foo baz1")
+
+			Convey("THEN the observer receives a single event for the search", func() {
+				So(matches, ShouldHaveLength, 2)
+				So(events, ShouldHaveLength, 1)
+				So(events[0].Method, ShouldEqual, SearchMethodFindAll)
+				So(events[0].MatchCount, ShouldEqual, 2)
+				So(events[0].HaystackLen, ShouldEqual, 8)
+			})
+		})
+
+		Convey("THEN its internal automatons are not registered", func() {
+			for _, stats := range RegisteredAutomata() {
+				So(stats.Name, ShouldNotEqual, "TestDynamicMatcherObserver")
+			}
+		})
+	})
+}
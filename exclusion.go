@@ -0,0 +1,91 @@
+package ahocorasick
+
+import (
+	"fmt"
+)
+
+// Span is a range of byte offsets in a haystack, from Start (inclusive) to End (exclusive).
+type Span struct {
+	// The starting position of the span.
+	Start uint
+	// The ending position of the span.
+	End uint
+}
+
+// FindAllExcluding is like [AhoCorasick.FindAll], but ignores every match overlapping one of the excluded spans,
+// e.g. matches inside comments or quoted strings that were already identified.
+//
+// Matches overlapping an excluded span are dropped during the search rather than filtered out afterwards, so they
+// never hide other matches: under leftmost semantics, a match starting before an excluded span and reaching into it
+// is ignored and the search continues with the matches that do not overlap it. An empty match overlaps a span if it
+// is strictly inside it.
+//
+// excluded must be sorted by Start; spans may overlap or touch each other, and empty spans exclude nothing.
+// FindAllExcluding panics with an error wrapping [ErrInvalidSpan] if the spans are not sorted or not within
+// the haystack.
+func (ac *AhoCorasick) FindAllExcluding(input string, excluded []Span) []Match {
+	segments := includedSegments(input, excluded)
+	span := ac.beginSearch(SearchMethodFindAll)
+	var result []Match
+	for _, segment := range segments {
+		result = append(result, ac.findAllIn(input, int(segment.Start), int(segment.End))...)
+	}
+	if result == nil {
+		result = []Match{}
+	}
+	span.end(len(input), len(result), false)
+	return result
+}
+
+// FindFirstExcluding is like [AhoCorasick.FindFirst], but ignores every match overlapping one of the excluded spans.
+// See [AhoCorasick.FindAllExcluding] for details.
+func (ac *AhoCorasick) FindFirstExcluding(input string, excluded []Span) *Match {
+	segments := includedSegments(input, excluded)
+	span := ac.beginSearch(SearchMethodFindFirst)
+	for _, segment := range segments {
+		if match := ac.findFirstIn(input, int(segment.Start), int(segment.End)); match != nil {
+			span.end(len(input), 1, true)
+			return match
+		}
+	}
+	span.end(len(input), 0, false)
+	return nil
+}
+
+// IsMatchExcluding is like [AhoCorasick.IsMatch], but ignores every match overlapping one of the excluded spans.
+// See [AhoCorasick.FindAllExcluding] for details.
+func (ac *AhoCorasick) IsMatchExcluding(input string, excluded []Span) bool {
+	segments := includedSegments(input, excluded)
+	span := ac.beginSearch(SearchMethodIsMatch)
+	for _, segment := range segments {
+		if ac.isMatchIn(input, int(segment.Start), int(segment.End)) {
+			span.end(len(input), 1, true)
+			return true
+		}
+	}
+	span.end(len(input), 0, false)
+	return false
+}
+
+// includedSegments returns the maximal spans of input that do not overlap any excluded span, in order.
+// A match is within one of these segments if and only if it does not overlap an excluded span.
+func includedSegments(input string, excluded []Span) []Span {
+	segments := make([]Span, 0, len(excluded)+1)
+	pos := uint(0)
+	for i, span := range excluded {
+		if span.Start > span.End || span.End > uint(len(input)) || (i > 0 && span.Start < excluded[i-1].Start) {
+			panic(fmt.Errorf("%w: excluded span %d [%d, %d) is not sorted or not within a haystack of length %d", ErrInvalidSpan, i, span.Start, span.End, len(input)))
+		}
+		if span.Start == span.End {
+			// An empty span does not exclude anything.
+			continue
+		}
+		if span.Start >= pos {
+			segments = append(segments, Span{Start: pos, End: span.Start})
+		}
+		if span.End > pos {
+			pos = span.End
+		}
+	}
+	return append(segments, Span{Start: pos, End: uint(len(input))})
+}
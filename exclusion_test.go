@@ -0,0 +1,96 @@
+package ahocorasick
+
+import (
+	"errors"
+	"fmt"
+	. "github.com/smartystreets/goconvey/convey"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func ExampleAhoCorasick_FindAllExcluding() {
+	automaton := NewAhoCorasickBuilder().
+		SetMatchKind(MatchKindLeftMostLongest).
+		Build([]string{"TODO", "TODO: remove"})
+	source := `x := "TODO: remove" // TODO`
+	quoted := Span{Start: 5, End: 19}
+	fmt.Println(automaton.FindAllExcluding(source, []Span{quoted}))
+	// Output: [{27 0 23}]
+}
+
+// randomSpans returns up to count random sorted spans within a haystack of length n, which may overlap.
+func randomSpans(random *rand.Rand, count int, n int) []Span {
+	spans := make([]Span, random.Intn(count+1))
+	for i := range spans {
+		start := uint(random.Intn(n + 1))
+		spans[i] = Span{Start: start, End: start + uint(random.Intn(n-int(start)+1))}
+	}
+	sort.Slice(spans, func(i, j int) bool {
+		return spans[i].Start < spans[j].Start
+	})
+	return spans
+}
+
+// overlapsAny reports whether match overlaps one of the spans. Empty spans overlap nothing.
+func overlapsAny(match Match, spans []Span) bool {
+	for _, span := range spans {
+		if span.Start == span.End {
+			continue
+		}
+		if match.Start < span.End && span.Start < match.End || span.Start < match.Start && match.Start < span.End {
+			return true
+		}
+	}
+	return false
+}
+
+func TestFindAllExcluding(t *testing.T) {
+	Convey("GIVEN random patterns, haystacks and excluded spans", t, func() {
+		random := rand.New(rand.NewSource(1))
+
+		Convey("THEN the matches are selected from the candidates that do not overlap an excluded span", func() {
+			for _, matchKind := range []MatchKind{MatchKindStandard, MatchKindLeftMostFirst, MatchKindLeftMostLongest} {
+				for i := 0; i < 300; i++ {
+					patterns := randomPatterns(random, 1+random.Intn(6), 3)
+					if matchKind != MatchKindStandard {
+						for j := range patterns {
+							patterns[j] += "b"
+						}
+					}
+					haystack := randomPatterns(random, 1, 30)[0]
+					excluded := randomSpans(random, 3, len(haystack))
+					var candidates []Match
+					for _, candidate := range NewAhoCorasick(patterns).FindOverlapping(haystack) {
+						if !overlapsAny(candidate, excluded) {
+							candidates = append(candidates, candidate)
+						}
+					}
+					expected := selectNonOverlapping(candidates, matchKind, -1)
+					automaton := NewAhoCorasickBuilder().SetMatchKind(matchKind).Build(patterns)
+					actual := automaton.FindAllExcluding(haystack, excluded)
+					if len(expected) == 0 {
+						So(actual, ShouldBeEmpty)
+						So(automaton.FindFirstExcluding(haystack, excluded), ShouldBeNil)
+						So(automaton.IsMatchExcluding(haystack, excluded), ShouldBeFalse)
+					} else {
+						So(actual, ShouldResemble, expected)
+						So(automaton.FindFirstExcluding(haystack, excluded), ShouldResemble, &expected[0])
+						So(automaton.IsMatchExcluding(haystack, excluded), ShouldBeTrue)
+					}
+				}
+			}
+		})
+	})
+
+	Convey("GIVEN excluded spans that are not sorted", t, func() {
+		automaton := NewAhoCorasick([]string{"foo"})
+
+		Convey("THEN the search panics", func() {
+			defer func() {
+				So(errors.Is(recover().(error), ErrInvalidSpan), ShouldBeTrue)
+			}()
+			automaton.FindAllExcluding("foofoo", []Span{{Start: 3, End: 4}, {Start: 1, End: 2}})
+		})
+	})
+}
@@ -0,0 +1,465 @@
+//! C bindings to the [aho-corasick](https://github.com/BurntSushi/aho-corasick) crate, as declared in
+//! `ahocorasick_rs.h` of the Go package.
+//!
+//! Arrays of matches are allocated with `malloc`, so that the caller can release them with `free`.
+//! Every function taking an automaton expects a pointer returned by `build_automaton` or `create_automaton`
+//! that has not been passed to `free_automaton`, and text that is valid for `text_len` bytes.
+//!
+//! No function panics on input that the automaton rejects, since unwinding out of an `extern "C"` function aborts
+//! the host process. Functions taking an `error` pointer store 1 in it when the automaton does not support the search,
+//! e.g. an unanchored search of an automaton built for anchored searches only, or when the span is out of bounds,
+//! and 0 otherwise. The other search functions report no match in that case.
+
+use aho_corasick::{
+    AhoCorasick, AhoCorasickBuilder, AhoCorasickKind, Anchored, Input, Match, MatchKind, StartKind,
+};
+use libc::{c_char, c_int, c_long, size_t};
+use std::{mem, ptr, slice};
+
+#[repr(C)]
+pub struct AhoCorasickBuilderOptions {
+    ascii_case_insensitive: c_int,
+    byte_classes: c_int,
+    dense_depth: *const size_t,
+    kind: *const size_t,
+    match_kind: size_t,
+    prefilter: c_int,
+    start_kind: size_t,
+}
+
+#[repr(C)]
+#[derive(Clone, Copy)]
+pub struct AhoCorasickMatch {
+    end: size_t,
+    pattern_index: size_t,
+    start: size_t,
+}
+
+impl From<Match> for AhoCorasickMatch {
+    fn from(m: Match) -> Self {
+        AhoCorasickMatch {
+            end: m.end(),
+            pattern_index: m.pattern().as_usize(),
+            start: m.start(),
+        }
+    }
+}
+
+unsafe fn bytes<'a>(data: *const c_char, len: size_t) -> &'a [u8] {
+    if len == 0 {
+        &[]
+    } else {
+        slice::from_raw_parts(data as *const u8, len)
+    }
+}
+
+unsafe fn patterns<'a>(
+    patterns: *const *const c_char,
+    lengths: *const size_t,
+    count: size_t,
+) -> Vec<&'a [u8]> {
+    if count == 0 {
+        return Vec::new();
+    }
+    let patterns = slice::from_raw_parts(patterns, count);
+    let lengths = slice::from_raw_parts(lengths, count);
+    patterns
+        .iter()
+        .zip(lengths)
+        .map(|(&pattern, &len)| bytes(pattern, len))
+        .collect()
+}
+
+fn kind(kind: size_t) -> AhoCorasickKind {
+    match kind {
+        1 => AhoCorasickKind::NoncontiguousNFA,
+        2 => AhoCorasickKind::ContiguousNFA,
+        _ => AhoCorasickKind::DFA,
+    }
+}
+
+fn match_kind(match_kind: size_t) -> MatchKind {
+    match match_kind {
+        2 => MatchKind::LeftmostLongest,
+        3 => MatchKind::LeftmostFirst,
+        _ => MatchKind::Standard,
+    }
+}
+
+fn start_kind(start_kind: size_t) -> StartKind {
+    match start_kind {
+        1 => StartKind::Both,
+        3 => StartKind::Anchored,
+        _ => StartKind::Unanchored,
+    }
+}
+
+/// Returns the input for text[start..end], or `None` if the span is out of bounds.
+unsafe fn input_in<'a>(
+    text: *const c_char,
+    text_len: size_t,
+    start: size_t,
+    end: size_t,
+) -> Option<Input<'a>> {
+    if start > end || end > text_len {
+        return None;
+    }
+    Some(Input::new(bytes(text, text_len)).span(start..end))
+}
+
+/// Stores whether the search failed in `error` and returns its result, or the default value if it failed.
+unsafe fn report<T: Default>(result: Option<T>, error: *mut c_int) -> T {
+    *error = result.is_none() as c_int;
+    result.unwrap_or_default()
+}
+
+/// Returns a single match allocated with `malloc`, or null if there is none.
+unsafe fn into_c_match(m: Option<Match>) -> *mut AhoCorasickMatch {
+    match m {
+        Some(m) => {
+            let out = libc::malloc(mem::size_of::<AhoCorasickMatch>()) as *mut AhoCorasickMatch;
+            *out = m.into();
+            out
+        }
+        None => ptr::null_mut(),
+    }
+}
+
+/// Returns the matches as an array allocated with `malloc` and stores their number in `count`.
+/// The array is null if there is no match.
+unsafe fn into_c_matches(
+    matches: impl Iterator<Item = Match>,
+    count: *mut c_long,
+) -> *mut AhoCorasickMatch {
+    let matches: Vec<AhoCorasickMatch> = matches.map(AhoCorasickMatch::from).collect();
+    *count = matches.len() as c_long;
+    if matches.is_empty() {
+        return ptr::null_mut();
+    }
+    let out =
+        libc::malloc(matches.len() * mem::size_of::<AhoCorasickMatch>()) as *mut AhoCorasickMatch;
+    ptr::copy_nonoverlapping(matches.as_ptr(), out, matches.len());
+    out
+}
+
+/// Builds an automaton using the given options. Returns null if the automaton cannot be built,
+/// e.g. because it would exceed the limits of the selected kind.
+#[no_mangle]
+pub unsafe extern "C" fn build_automaton(
+    pattern_data: *const *const c_char,
+    pattern_lengths: *const size_t,
+    num_patterns: size_t,
+    options: *const AhoCorasickBuilderOptions,
+) -> *mut AhoCorasick {
+    let options = &*options;
+    let mut builder = AhoCorasickBuilder::new();
+    builder
+        .ascii_case_insensitive(options.ascii_case_insensitive != 0)
+        .byte_classes(options.byte_classes != 0)
+        .prefilter(options.prefilter != 0)
+        .match_kind(match_kind(options.match_kind))
+        .start_kind(start_kind(options.start_kind));
+    if !options.dense_depth.is_null() {
+        builder.dense_depth(*options.dense_depth);
+    }
+    if !options.kind.is_null() {
+        builder.kind(Some(kind(*options.kind)));
+    }
+    match builder.build(patterns(pattern_data, pattern_lengths, num_patterns)) {
+        Ok(automaton) => Box::into_raw(Box::new(automaton)),
+        Err(_) => ptr::null_mut(),
+    }
+}
+
+/// Builds an automaton using the default options. Returns null if the automaton cannot be built.
+#[no_mangle]
+pub unsafe extern "C" fn create_automaton(
+    pattern_data: *const *const c_char,
+    pattern_lengths: *const size_t,
+    num_patterns: size_t,
+) -> *mut AhoCorasick {
+    match AhoCorasick::new(patterns(pattern_data, pattern_lengths, num_patterns)) {
+        Ok(automaton) => Box::into_raw(Box::new(automaton)),
+        Err(_) => ptr::null_mut(),
+    }
+}
+
+/// Counts the matches of a non-overlapping or, if `overlapping` is not 0, an overlapping search.
+/// If `pattern_counts` is not null, it must have room for one count per pattern, which is incremented for every match.
+#[no_mangle]
+pub unsafe extern "C" fn count_matches(
+    automaton: *const AhoCorasick,
+    text: *const c_char,
+    text_len: size_t,
+    overlapping: c_int,
+    pattern_counts: *mut size_t,
+    error: *mut c_int,
+) -> size_t {
+    let automaton = &*automaton;
+    let haystack = bytes(text, text_len);
+    let mut pattern_counts = if pattern_counts.is_null() {
+        None
+    } else {
+        Some(slice::from_raw_parts_mut(
+            pattern_counts,
+            automaton.patterns_len(),
+        ))
+    };
+    let mut total = 0;
+    let mut count = |m: Match| {
+        total += 1;
+        if let Some(counts) = pattern_counts.as_mut() {
+            counts[m.pattern().as_usize()] += 1;
+        }
+    };
+    let searched = if overlapping != 0 {
+        automaton
+            .try_find_overlapping_iter(haystack)
+            .map(|matches| matches.for_each(&mut count))
+    } else {
+        automaton
+            .try_find_iter(haystack)
+            .map(|matches| matches.for_each(&mut count))
+    };
+    report(searched.ok().map(|()| total), error)
+}
+
+/// Returns the first match, or null if there is none or the automaton does not support the search.
+#[no_mangle]
+pub unsafe extern "C" fn find(
+    automaton: *const AhoCorasick,
+    text: *const c_char,
+    text_len: size_t,
+) -> *mut AhoCorasickMatch {
+    into_c_match((*automaton).try_find(bytes(text, text_len)).ok().flatten())
+}
+
+/// Returns the first match within text[start..end], or null if there is none.
+#[no_mangle]
+pub unsafe extern "C" fn find_in(
+    automaton: *const AhoCorasick,
+    text: *const c_char,
+    text_len: size_t,
+    start: size_t,
+    end: size_t,
+    error: *mut c_int,
+) -> *mut AhoCorasickMatch {
+    let found =
+        input_in(text, text_len, start, end).and_then(|input| (*automaton).try_find(input).ok());
+    into_c_match(report(found, error))
+}
+
+/// Returns all non-overlapping matches.
+#[no_mangle]
+pub unsafe extern "C" fn find_iter(
+    automaton: *const AhoCorasick,
+    text: *const c_char,
+    text_len: size_t,
+    found_count: *mut c_long,
+) -> *mut AhoCorasickMatch {
+    let matches = (*automaton).try_find_iter(bytes(text, text_len));
+    into_c_matches(matches.into_iter().flatten(), found_count)
+}
+
+/// Returns all non-overlapping matches within text[start..end].
+#[no_mangle]
+pub unsafe extern "C" fn find_iter_in(
+    automaton: *const AhoCorasick,
+    text: *const c_char,
+    text_len: size_t,
+    start: size_t,
+    end: size_t,
+    found_count: *mut c_long,
+    error: *mut c_int,
+) -> *mut AhoCorasickMatch {
+    let matches: Vec<Match> = report(
+        input_in(text, text_len, start, end)
+            .and_then(|input| (*automaton).try_find_iter(input).ok())
+            .map(|matches| matches.collect()),
+        error,
+    );
+    into_c_matches(matches.into_iter(), found_count)
+}
+
+/// Returns at most `limit` non-overlapping matches. `truncated` is set to 1 if there are more, which is found out
+/// by looking for one more match, and to 0 otherwise.
+#[no_mangle]
+pub unsafe extern "C" fn find_iter_n(
+    automaton: *const AhoCorasick,
+    text: *const c_char,
+    text_len: size_t,
+    limit: size_t,
+    found_count: *mut c_long,
+    truncated: *mut c_int,
+    error: *mut c_int,
+) -> *mut AhoCorasickMatch {
+    let mut matches: Vec<Match> = report(
+        (*automaton)
+            .try_find_iter(bytes(text, text_len))
+            .ok()
+            .map(|matches| matches.take(limit.saturating_add(1)).collect()),
+        error,
+    );
+    *truncated = (matches.len() > limit) as c_int;
+    matches.truncate(limit);
+    into_c_matches(matches.into_iter(), found_count)
+}
+
+/// Returns the longest prefix of the text that is a pattern, or null if there is none.
+/// The automaton must support anchored searches and should use leftmost-longest semantics.
+#[no_mangle]
+pub unsafe extern "C" fn find_prefix(
+    automaton: *const AhoCorasick,
+    text: *const c_char,
+    text_len: size_t,
+    error: *mut c_int,
+) -> *mut AhoCorasickMatch {
+    let input = Input::new(bytes(text, text_len)).anchored(Anchored::Yes);
+    into_c_match(report((*automaton).try_find(input).ok(), error))
+}
+
+/// Returns every prefix of the text that is a pattern, longest first.
+/// The automaton must support anchored searches and should use leftmost-longest semantics.
+#[no_mangle]
+pub unsafe extern "C" fn find_prefixes(
+    automaton: *const AhoCorasick,
+    text: *const c_char,
+    text_len: size_t,
+    found_count: *mut c_long,
+    error: *mut c_int,
+) -> *mut AhoCorasickMatch {
+    let haystack = bytes(text, text_len);
+    let mut prefixes = Vec::new();
+    let mut end = text_len;
+    // Anchored overlapping searches are not supported, so shorter prefixes are found by shrinking the haystack.
+    *error = 0;
+    loop {
+        let input = Input::new(haystack).span(0..end).anchored(Anchored::Yes);
+        match (*automaton).try_find(input) {
+            Ok(Some(m)) => {
+                prefixes.push(m);
+                if m.end() == 0 {
+                    break;
+                }
+                end = m.end() - 1;
+            }
+            Ok(None) => break,
+            Err(_) => {
+                *error = 1;
+                prefixes.clear();
+                break;
+            }
+        }
+    }
+    into_c_matches(prefixes.into_iter(), found_count)
+}
+
+/// Returns all overlapping matches. The automaton must use standard semantics and support unanchored searches.
+#[no_mangle]
+pub unsafe extern "C" fn find_overlapping_iter(
+    automaton: *const AhoCorasick,
+    text: *const c_char,
+    text_len: size_t,
+    found_count: *mut c_long,
+    error: *mut c_int,
+) -> *mut AhoCorasickMatch {
+    let matches: Vec<Match> = report(
+        (*automaton)
+            .try_find_overlapping_iter(bytes(text, text_len))
+            .ok()
+            .map(|matches| matches.collect()),
+        error,
+    );
+    into_c_matches(matches.into_iter(), found_count)
+}
+
+/// Releases an automaton. Null is ignored.
+#[no_mangle]
+pub unsafe extern "C" fn free_automaton(automaton: *mut AhoCorasick) {
+    if !automaton.is_null() {
+        drop(Box::from_raw(automaton));
+    }
+}
+
+/// Returns the kind of the automaton: 1 for a noncontiguous NFA, 2 for a contiguous NFA and 3 for a DFA.
+#[no_mangle]
+pub unsafe extern "C" fn get_kind(automaton: *const AhoCorasick) -> c_int {
+    match (*automaton).kind() {
+        AhoCorasickKind::NoncontiguousNFA => 1,
+        AhoCorasickKind::ContiguousNFA => 2,
+        AhoCorasickKind::DFA => 3,
+        _ => 0,
+    }
+}
+
+/// Returns 1 if the text contains a match, 0 otherwise.
+#[no_mangle]
+pub unsafe extern "C" fn is_match(
+    automaton: *const AhoCorasick,
+    text: *const c_char,
+    text_len: size_t,
+) -> c_int {
+    let input = Input::new(bytes(text, text_len)).earliest(true);
+    (*automaton).try_find(input).map_or(false, |m| m.is_some()) as c_int
+}
+
+/// Returns 1 if text[start..end] contains a match, 0 otherwise.
+#[no_mangle]
+pub unsafe extern "C" fn is_match_in(
+    automaton: *const AhoCorasick,
+    text: *const c_char,
+    text_len: size_t,
+    start: size_t,
+    end: size_t,
+    error: *mut c_int,
+) -> c_int {
+    let found = input_in(text, text_len, start, end)
+        .and_then(|input| (*automaton).try_find(input.earliest(true)).ok())
+        .map(|m| m.is_some());
+    report(found, error) as c_int
+}
+
+/// Returns the heap memory used by the automaton, in bytes.
+#[no_mangle]
+pub unsafe extern "C" fn memory_usage(automaton: *const AhoCorasick) -> size_t {
+    (*automaton).memory_usage()
+}
+
+/// Sets the bit of every pattern occurring in the text in `pattern_set`, which holds `pattern_count` bits in 64-bit
+/// words, and returns the number of bits set. The search stops once every pattern has been seen.
+/// The automaton must use standard semantics and support unanchored searches.
+#[no_mangle]
+pub unsafe extern "C" fn which_match(
+    automaton: *const AhoCorasick,
+    text: *const c_char,
+    text_len: size_t,
+    pattern_set: *mut u64,
+    pattern_count: size_t,
+    error: *mut c_int,
+) -> size_t {
+    let matches = match (*automaton).try_find_overlapping_iter(bytes(text, text_len)) {
+        Ok(matches) => matches,
+        Err(_) => {
+            *error = 1;
+            return 0;
+        }
+    };
+    *error = 0;
+    if pattern_count == 0 {
+        return 0;
+    }
+    let words = slice::from_raw_parts_mut(pattern_set, (pattern_count + 63) / 64);
+    let mut seen = 0;
+    for m in matches {
+        let id = m.pattern().as_usize();
+        let bit = 1u64 << (id % 64);
+        if words[id / 64] & bit == 0 {
+            words[id / 64] |= bit;
+            seen += 1;
+            if seen == pattern_count {
+                break;
+            }
+        }
+    }
+    seen
+}
@@ -0,0 +1,68 @@
+package ahocorasick
+
+// FindLast returns the location of the last match in the haystack, or nil if there is none.
+//
+// The search runs a reversed automaton, built from the reversed patterns with the same settings as this one,
+// over the reversed haystack, so the haystack is scanned from its end and the search stops at the first match found.
+// The reversed automaton is built on first use and kept for the lifetime of this automaton; the reversed haystack
+// is copied for every search.
+//
+// Which match is the last one depends on the match kind of this automaton:
+//   - [MatchKindStandard]: the match starting last, which is the first one found when scanning backwards.
+//     Among the matches starting there, the longest.
+//   - [MatchKindLeftMostFirst]: the match ending last. Among the matches ending there, the one of the pattern
+//     given first to the builder.
+//   - [MatchKindLeftMostLongest]: the match ending last. Among the matches ending there, the longest.
+//
+// The match ending last is not always the last match reported by [AhoCorasick.FindAll], since a non-overlapping
+// search from the start of the haystack may have consumed it as part of an earlier match. For example, with the
+// patterns "ab" and "bc", FindAll reports "ab" in the haystack "abc" while FindLast reports "bc".
+//
+// FindLast panics with an error wrapping [ErrUnsupportedSearch] if this automaton only supports anchored searches.
+func (ac *AhoCorasick) FindLast(input string) *Match {
+	ac.requireUnanchored("FindLast")
+	span := ac.beginSearch(SearchMethodFindLast)
+	reversed := reverseString(input)
+	match := ac.reverse().FindFirst(reversed)
+	if match != nil && ac.config.UTF8Aligned {
+		// The offsets of the reversed haystack mirror those of the haystack.
+		n := uint(len(input))
+		aligned := func(match Match) bool {
+			return isUTF8Boundary(input, n-match.End) && isUTF8Boundary(input, n-match.Start)
+		}
+		match = firstOf(ac.reverse().realign(reversed, 0, len(reversed), []Match{*match}, 1, aligned))
+	}
+	if match == nil {
+		span.end(len(input), 0, false)
+		return nil
+	}
+	span.end(len(input), 1, true)
+	return &Match{
+		End:          uint(len(input)) - match.Start,
+		PatternIndex: match.PatternIndex,
+		Start:        uint(len(input)) - match.End,
+	}
+}
+
+// reverse returns the automaton of the reversed patterns of ac, which is built on first use.
+func (ac *AhoCorasick) reverse() *AhoCorasick {
+	ac.companionMu.Lock()
+	defer ac.companionMu.Unlock()
+	if ac.reversed == nil {
+		patterns := make([]string, ac.patternCount)
+		for i, pattern := range ac.patterns {
+			patterns[i] = reverseString(pattern)
+		}
+		ac.reversed = NewAhoCorasickBuilderFromConfig(ac.config).SetUTF8Aligned(false).Build(patterns)
+	}
+	return ac.reversed
+}
+
+// reverseString reverses the bytes of s.
+func reverseString(s string) string {
+	reversed := make([]byte, len(s))
+	for i := 0; i < len(s); i++ {
+		reversed[len(s)-1-i] = s[i]
+	}
+	return string(reversed)
+}
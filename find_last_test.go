@@ -0,0 +1,98 @@
+package ahocorasick
+
+import (
+	"errors"
+	"fmt"
+	. "github.com/smartystreets/goconvey/convey"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func ExampleAhoCorasick_FindLast() {
+	automaton := NewAhoCorasickBuilder().
+		SetMatchKind(MatchKindLeftMostLongest).
+		Build([]string{".tar", ".gz", ".tar.gz"})
+	haystack := "archive.tar.gz"
+	match := automaton.FindLast(haystack)
+	fmt.Println(haystack[match.Start:match.End])
+	// Output: .tar.gz
+}
+
+// expectedLastMatch picks the last of all overlapping matches as documented by [AhoCorasick.FindLast].
+func expectedLastMatch(candidates []Match, matchKind MatchKind) *Match {
+	if len(candidates) == 0 {
+		return nil
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		switch matchKind {
+		case MatchKindStandard:
+			if a.Start != b.Start {
+				return a.Start > b.Start
+			}
+			if a.End != b.End {
+				return a.End > b.End
+			}
+		case MatchKindLeftMostFirst:
+			if a.End != b.End {
+				return a.End > b.End
+			}
+		case MatchKindLeftMostLongest:
+			if a.End != b.End {
+				return a.End > b.End
+			}
+			if a.Start != b.Start {
+				return a.Start < b.Start
+			}
+		}
+		return a.PatternIndex < b.PatternIndex
+	})
+	return &candidates[0]
+}
+
+func TestFindLast(t *testing.T) {
+	Convey("GIVEN random patterns and haystacks", t, func() {
+		random := rand.New(rand.NewSource(1))
+
+		Convey("THEN FindLast picks the last of all overlapping matches according to the match kind", func() {
+			for _, matchKind := range []MatchKind{MatchKindStandard, MatchKindLeftMostFirst, MatchKindLeftMostLongest} {
+				for _, caseInsensitive := range []bool{false, true} {
+					for i := 0; i < 200; i++ {
+						patterns := randomPatterns(random, 1+random.Intn(6), 4)
+						if matchKind != MatchKindStandard {
+							for j := range patterns {
+								patterns[j] += "b"
+							}
+						}
+						haystack := randomPatterns(random, 1, 30)[0]
+						builder := NewAhoCorasickBuilder().SetAsciiCaseInsensitive(caseInsensitive)
+						candidates := builder.Clone().Build(patterns).FindOverlapping(haystack)
+						actual := builder.SetMatchKind(matchKind).Build(patterns).FindLast(haystack)
+						So(actual, ShouldResemble, expectedLastMatch(candidates, matchKind))
+					}
+				}
+			}
+		})
+	})
+
+	Convey("GIVEN patterns that overlap", t, func() {
+		automaton := NewAhoCorasickBuilder().SetMatchKind(MatchKindLeftMostFirst).Build([]string{"ab", "bc"})
+
+		Convey("THEN FindLast may report a match that FindAll does not", func() {
+			So(automaton.FindAll("abc"), ShouldResemble, []Match{{End: 2, PatternIndex: 0, Start: 0}})
+			So(automaton.FindLast("abc"), ShouldResemble, &Match{End: 3, PatternIndex: 1, Start: 1})
+		})
+	})
+
+	Convey("GIVEN an automaton supporting only anchored searches", t, func() {
+		automaton := NewAhoCorasickBuilder().SetStartKind(StartKindAnchored).Build([]string{"foo"})
+
+		Convey("THEN FindLast panics", func() {
+			defer func() {
+				So(errors.Is(recover().(error), ErrUnsupportedSearch), ShouldBeTrue)
+			}()
+			automaton.FindLast("foo")
+		})
+	})
+}
@@ -0,0 +1,52 @@
+package ahocorasick
+
+/*
+#include "./ahocorasick_rs.h"
+*/
+import "C"
+import (
+	"runtime"
+	"unsafe"
+)
+
+// FindN is like [AhoCorasick.FindAll], but stops searching once n matches were found.
+// It also reports whether the result was truncated, i.e. whether [AhoCorasick.FindAll] would have returned
+// more than n matches.
+//
+// The search stops at the match following the n-th one, so at most n+1 matches are ever produced and the rest
+// of the haystack is not scanned. This bounds the work and memory spent on inputs with an unexpectedly large number
+// of matches, and answers questions like "are there at least 3 matches" without finding all of them.
+// If n is negative, there is no limit and the result is never truncated.
+//
+// FindN panics with an error wrapping [ErrUnsupportedSearch] if this automaton only supports anchored searches.
+func (ac *AhoCorasick) FindN(input string, n int) ([]Match, bool) {
+	ac.requireUnanchored("FindN")
+	if n < 0 {
+		return ac.FindAll(input), false
+	}
+	span := ac.beginSearch(SearchMethodFindN)
+	limit := n
+	if ac.config.UTF8Aligned {
+		// The match following the n-th one must be aligned as well to tell whether the result is truncated.
+		limit++
+	}
+	cText := (*C.char)(unsafe.Pointer(unsafe.StringData(input)))
+	foundCount := C.long(0)
+	truncated := C.int(0)
+	cErr := C.int(0)
+	cMatches := C.find_iter_n(ac.native(), cText, C.size_t(len(input)), C.size_t(limit), &foundCount, &truncated, &cErr)
+	runtime.KeepAlive(cText)
+	runtime.KeepAlive(input)
+	runtime.KeepAlive(ac)
+	checkNativeSearch(cErr)
+	result := takeCMatches(cMatches, foundCount)
+	if ac.config.UTF8Aligned {
+		result = ac.realign(input, 0, len(input), result, limit, alignedIn(input))
+		truncated = 0
+		if len(result) > n {
+			result, truncated = result[:n], 1
+		}
+	}
+	span.end(len(input), len(result), truncated != 0)
+	return result, truncated != 0
+}
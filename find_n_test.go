@@ -0,0 +1,70 @@
+package ahocorasick
+
+import (
+	"fmt"
+	. "github.com/smartystreets/goconvey/convey"
+	"math/rand"
+	"testing"
+)
+
+func ExampleAhoCorasick_FindN() {
+	automaton := NewAhoCorasick([]string{"foo", "bar"})
+	matches, truncated := automaton.FindN("foo bar foo bar", 3)
+	fmt.Println(len(matches), truncated)
+	// Output: 3 true
+}
+
+func TestFindN(t *testing.T) {
+	Convey("GIVEN random patterns and haystacks", t, func() {
+		random := rand.New(rand.NewSource(1))
+
+		Convey("THEN FindN returns a prefix of FindAll and reports whether it was truncated", func() {
+			for _, matchKind := range []MatchKind{MatchKindStandard, MatchKindLeftMostFirst, MatchKindLeftMostLongest} {
+				for i := 0; i < 100; i++ {
+					patterns := randomPatterns(random, 1+random.Intn(6), 3)
+					haystack := randomPatterns(random, 1, 30)[0]
+					automaton := NewAhoCorasickBuilder().SetMatchKind(matchKind).Build(patterns)
+					all := automaton.FindAll(haystack)
+					for n := 0; n <= len(all)+1; n++ {
+						matches, truncated := automaton.FindN(haystack, n)
+						So(truncated, ShouldEqual, len(all) > n)
+						if n >= len(all) {
+							So(matches, ShouldResemble, all)
+						} else {
+							So(matches, ShouldResemble, all[:n])
+						}
+					}
+				}
+			}
+		})
+	})
+
+	Convey("GIVEN an observed automaton", t, func() {
+		var events []SearchEvent
+		automaton := NewAhoCorasickBuilder().
+			SetObserver(ObserverFunc(func(event SearchEvent) {
+				events = append(events, event)
+			})).
+			Build([]string{"a"})
+
+		Convey("WHEN the result of FindN is truncated", func() {
+			automaton.FindN("aaa", 2)
+
+			Convey("THEN the search is reported as short-circuited", func() {
+				So(events, ShouldHaveLength, 1)
+				So(events[0].Method, ShouldEqual, SearchMethodFindN)
+				So(events[0].MatchCount, ShouldEqual, 2)
+				So(events[0].ShortCircuited, ShouldBeTrue)
+			})
+		})
+
+		Convey("WHEN the limit is negative", func() {
+			matches, truncated := automaton.FindN("aaa", -1)
+
+			Convey("THEN all matches are returned", func() {
+				So(matches, ShouldHaveLength, 3)
+				So(truncated, ShouldBeFalse)
+			})
+		})
+	})
+}
@@ -0,0 +1,149 @@
+package ahocorasick
+
+import (
+	"fmt"
+	"html"
+	"sort"
+	"strings"
+)
+
+// HighlightRenderer renders a haystack with highlighted matches, as produced by [AhoCorasick.Highlight].
+type HighlightRenderer interface {
+	// RenderText writes text that is not part of a match.
+	RenderText(buf *strings.Builder, text string)
+	// RenderMatch writes a highlighted span, made of one or more overlapping or adjacent matches of the given
+	// patterns, listed in the order in which their first match starts.
+	RenderMatch(buf *strings.Builder, text string, patterns []uint)
+}
+
+// Highlight returns the haystack rendered by renderer, with the matches found by [AhoCorasick.FindAll] highlighted.
+// Adjacent matches are merged into a single highlighted span.
+func (ac *AhoCorasick) Highlight(input string, renderer HighlightRenderer) string {
+	return HighlightMatches(input, ac.FindAll(input), renderer)
+}
+
+// HighlightMatches returns the haystack rendered by renderer, with the given matches highlighted.
+//
+// The matches may come from any search, in any order. Matches that overlap or are adjacent to each other are merged
+// into a single highlighted span, and empty matches are ignored.
+func HighlightMatches(input string, matches []Match, renderer HighlightRenderer) string {
+	sorted := make([]Match, 0, len(matches))
+	for _, match := range matches {
+		if match.Start != match.End {
+			sorted = append(sorted, match)
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Start < sorted[j].Start
+	})
+	var buf strings.Builder
+	last := uint(0)
+	for i := 0; i < len(sorted); {
+		start, end := sorted[i].Start, sorted[i].End
+		patterns := []uint{sorted[i].PatternIndex}
+		for i++; i < len(sorted) && sorted[i].Start <= end; i++ {
+			if sorted[i].End > end {
+				end = sorted[i].End
+			}
+			if !containsPattern(patterns, sorted[i].PatternIndex) {
+				patterns = append(patterns, sorted[i].PatternIndex)
+			}
+		}
+		if start > last {
+			renderer.RenderText(&buf, input[last:start])
+		}
+		renderer.RenderMatch(&buf, input[start:end], patterns)
+		last = end
+	}
+	if last < uint(len(input)) {
+		renderer.RenderText(&buf, input[last:])
+	}
+	return buf.String()
+}
+
+func containsPattern(patterns []uint, pattern uint) bool {
+	for _, candidate := range patterns {
+		if candidate == pattern {
+			return true
+		}
+	}
+	return false
+}
+
+// HTMLRenderer is a [HighlightRenderer] producing HTML, where highlighted spans are wrapped in <mark> elements
+// and all text is escaped.
+type HTMLRenderer struct {
+	// If not empty, every <mark> element gets a class made of ClassPrefix followed by the ID of the first pattern
+	// of its span, e.g. "match-0".
+	ClassPrefix string
+}
+
+// RenderText writes the escaped text.
+func (r HTMLRenderer) RenderText(buf *strings.Builder, text string) {
+	buf.WriteString(html.EscapeString(text))
+}
+
+// RenderMatch writes the escaped text wrapped in a <mark> element.
+func (r HTMLRenderer) RenderMatch(buf *strings.Builder, text string, patterns []uint) {
+	if r.ClassPrefix != "" {
+		fmt.Fprintf(buf, `<mark class="%s%d">`, html.EscapeString(r.ClassPrefix), patterns[0])
+	} else {
+		buf.WriteString("<mark>")
+	}
+	buf.WriteString(html.EscapeString(text))
+	buf.WriteString("</mark>")
+}
+
+// DefaultANSIColors are the SGR parameters used by an [ANSIRenderer] without colors: bold red, green, yellow,
+// blue, magenta and cyan.
+var DefaultANSIColors = []string{"1;31", "1;32", "1;33", "1;34", "1;35", "1;36"}
+
+// ANSIRenderer is a [HighlightRenderer] for terminals, where highlighted spans are colored using ANSI escape
+// sequences, with a distinct color per pattern or group of patterns. Text is written unchanged.
+type ANSIRenderer struct {
+	// The SGR parameters of the colors to use, e.g. "1;31" for bold red. [DefaultANSIColors] is used if empty.
+	Colors []string
+	// Group maps a pattern ID to its group. Spans are colored according to the group of their first pattern,
+	// cycling through the colors. If nil, every pattern is its own group.
+	Group func(pattern uint) int
+}
+
+// RenderText writes the text unchanged.
+func (r ANSIRenderer) RenderText(buf *strings.Builder, text string) {
+	buf.WriteString(text)
+}
+
+// RenderMatch writes the text colored according to the group of its first pattern.
+func (r ANSIRenderer) RenderMatch(buf *strings.Builder, text string, patterns []uint) {
+	colors := r.Colors
+	if len(colors) == 0 {
+		colors = DefaultANSIColors
+	}
+	group := int(patterns[0])
+	if r.Group != nil {
+		group = r.Group(patterns[0])
+	}
+	color := colors[(group%len(colors)+len(colors))%len(colors)]
+	buf.WriteString("\x1b[" + color + "m")
+	buf.WriteString(text)
+	buf.WriteString("\x1b[0m")
+}
+
+// AffixRenderer is a [HighlightRenderer] wrapping highlighted spans in a custom prefix and suffix.
+// Text is written unchanged.
+type AffixRenderer struct {
+	Prefix string
+	Suffix string
+}
+
+// RenderText writes the text unchanged.
+func (r AffixRenderer) RenderText(buf *strings.Builder, text string) {
+	buf.WriteString(text)
+}
+
+// RenderMatch writes the text between the prefix and the suffix.
+func (r AffixRenderer) RenderMatch(buf *strings.Builder, text string, patterns []uint) {
+	buf.WriteString(r.Prefix)
+	buf.WriteString(text)
+	buf.WriteString(r.Suffix)
+}
@@ -0,0 +1,60 @@
+package ahocorasick
+
+import (
+	"fmt"
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+func ExampleAhoCorasick_Highlight() {
+	automaton := NewAhoCorasick([]string{"fox", "dog"})
+	haystack := "The <quick> fox jumps over the lazy dog"
+	fmt.Println(automaton.Highlight(haystack, HTMLRenderer{}))
+	fmt.Println(automaton.Highlight(haystack, AffixRenderer{Prefix: "[", Suffix: "]"}))
+	// Output:
+	// The &lt;quick&gt; <mark>fox</mark> jumps over the lazy <mark>dog</mark>
+	// The <quick> [fox] jumps over the lazy [dog]
+}
+
+func TestHighlight(t *testing.T) {
+	Convey("GIVEN overlapping and adjacent matches", t, func() {
+		automaton := NewAhoCorasick([]string{"abc", "cd", "de", "x"})
+		haystack := "xabcdey xx"
+		matches := automaton.FindOverlapping(haystack)
+
+		Convey("THEN they are merged into single spans", func() {
+			So(HighlightMatches(haystack, matches, AffixRenderer{Prefix: "[", Suffix: "]"}), ShouldEqual, "[xabcde]y [xx]")
+		})
+
+		Convey("THEN the HTML renderer adds the class of the first pattern of every span", func() {
+			So(HighlightMatches(haystack, matches, HTMLRenderer{ClassPrefix: "m"}), ShouldEqual,
+				`<mark class="m3">xabcde</mark>y <mark class="m3">xx</mark>`)
+		})
+	})
+
+	Convey("GIVEN an ANSI renderer with groups", t, func() {
+		automaton := NewAhoCorasick([]string{"error", "warning", "fatal"})
+		renderer := ANSIRenderer{
+			Colors: []string{"31", "33"},
+			Group: func(pattern uint) int {
+				if pattern == 1 {
+					return 1
+				}
+				return 0
+			},
+		}
+
+		Convey("THEN every span is colored according to its group", func() {
+			So(automaton.Highlight("warning: fatal error", renderer), ShouldEqual,
+				"\x1b[33mwarning\x1b[0m: \x1b[31mfatal\x1b[0m \x1b[31merror\x1b[0m")
+		})
+	})
+
+	Convey("GIVEN an ANSI renderer without colors", t, func() {
+		automaton := NewAhoCorasick([]string{"a", "b"})
+
+		Convey("THEN the default colors are used per pattern", func() {
+			So(automaton.Highlight("a b", ANSIRenderer{}), ShouldEqual, "\x1b[1;31ma\x1b[0m \x1b[1;32mb\x1b[0m")
+		})
+	})
+}
@@ -0,0 +1,166 @@
+package ahocorasick
+
+import (
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// TrackedAutomaton describes an automaton created while leak tracking was enabled. See [SetLeakTracking].
+type TrackedAutomaton struct {
+	// The name given by [AhoCorasickBuilder.SetName].
+	Name string
+	// The native memory used by the automaton. See [AhoCorasick.MemoryUsage].
+	NativeBytes uint
+	// The time at which the automaton was created.
+	Created time.Time
+	// The stack trace of the goroutine that created the automaton.
+	Stack string
+}
+
+// LiveAutomataStats summarises the automatons whose native memory has not been released yet.
+type LiveAutomataStats struct {
+	// The number of live automatons.
+	Count int64
+	// The total native memory used by the live automatons.
+	NativeBytes uint64
+	// The live automatons that were created while leak tracking was enabled, oldest first.
+	Tracked []TrackedAutomaton
+}
+
+// trackingRecord is the leak tracking state of a single automaton.
+// It is allocated separately from the automaton so that tracking does not keep the automaton alive.
+type trackingRecord struct {
+	automaton TrackedAutomaton
+	sequence  uint64
+}
+
+var liveTotals struct {
+	count       atomic.Int64
+	nativeBytes atomic.Uint64
+}
+
+var leakTracking = struct {
+	sync.Mutex
+	enabled  atomic.Bool
+	sequence uint64
+	live     map[*trackingRecord]struct{}
+	leaked   []TrackedAutomaton
+}{live: make(map[*trackingRecord]struct{})}
+
+// SetLeakTracking enables or disables leak tracking, a debug mode intended for tests and staging environments.
+//
+// While enabled, every newly created automaton is recorded together with the stack trace of its creation,
+// which makes it possible to find out where automatons that are never released are created (see [LiveAutomata])
+// and which automatons were garbage collected without being closed (see [LeakedAutomata]).
+// Capturing stack traces makes creating automatons noticeably slower.
+//
+// Disabling leak tracking does not forget the automatons that were already recorded.
+func SetLeakTracking(enabled bool) {
+	leakTracking.enabled.Store(enabled)
+}
+
+// LiveAutomata returns the number and total native memory of all automatons that have been neither closed
+// nor garbage collected yet, along with the details of those created while leak tracking was enabled.
+//
+// The totals are maintained regardless of whether leak tracking is enabled.
+func LiveAutomata() LiveAutomataStats {
+	leakTracking.Lock()
+	records := make([]*trackingRecord, 0, len(leakTracking.live))
+	for record := range leakTracking.live {
+		records = append(records, record)
+	}
+	leakTracking.Unlock()
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].sequence < records[j].sequence
+	})
+	tracked := make([]TrackedAutomaton, len(records))
+	for i, record := range records {
+		tracked[i] = record.automaton
+	}
+	return LiveAutomataStats{
+		Count:       liveTotals.count.Load(),
+		NativeBytes: liveTotals.nativeBytes.Load(),
+		Tracked:     tracked,
+	}
+}
+
+// LeakedAutomata returns the tracked automatons that were garbage collected without [AhoCorasick.Close] being
+// called, in the order in which they were collected, and forgets them.
+//
+// Finalizers run asynchronously, so call [runtime.GC] (possibly more than once) before this function when checking
+// for leaks in tests.
+func LeakedAutomata() []TrackedAutomaton {
+	leakTracking.Lock()
+	defer leakTracking.Unlock()
+	leaked := leakTracking.leaked
+	leakTracking.leaked = nil
+	return leaked
+}
+
+// track accounts for a newly created automaton and records it if leak tracking is enabled.
+func track(ac *AhoCorasick) *trackingRecord {
+	liveTotals.count.Add(1)
+	liveTotals.nativeBytes.Add(uint64(ac.nativeBytes))
+	if !leakTracking.enabled.Load() {
+		return nil
+	}
+	record := &trackingRecord{
+		automaton: TrackedAutomaton{
+			Name:        ac.name,
+			NativeBytes: ac.nativeBytes,
+			Created:     time.Now(),
+			Stack:       callerStack(),
+		},
+	}
+	leakTracking.Lock()
+	leakTracking.sequence++
+	record.sequence = leakTracking.sequence
+	leakTracking.live[record] = struct{}{}
+	leakTracking.Unlock()
+	return record
+}
+
+// untrack accounts for a released automaton. Tracked automatons released by the garbage collector rather than
+// by [AhoCorasick.Close] are reported by [LeakedAutomata].
+func untrack(ac *AhoCorasick, closed bool) {
+	liveTotals.count.Add(-1)
+	liveTotals.nativeBytes.Add(-uint64(ac.nativeBytes))
+	if ac.tracking == nil {
+		return
+	}
+	leakTracking.Lock()
+	delete(leakTracking.live, ac.tracking)
+	if !closed {
+		leakTracking.leaked = append(leakTracking.leaked, ac.tracking.automaton)
+	}
+	leakTracking.Unlock()
+}
+
+// callerStack returns the stack trace of the goroutine creating an automaton, excluding this package's frames.
+func callerStack() string {
+	pcs := make([]uintptr, 64)
+	n := runtime.Callers(1, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	var builder strings.Builder
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, "github.com/tmikus/ahocorasick_rs.") ||
+			strings.HasSuffix(frame.File, "_test.go") {
+			builder.WriteString(frame.Function)
+			builder.WriteString("\n\t")
+			builder.WriteString(frame.File)
+			builder.WriteString(":")
+			builder.WriteString(strconv.Itoa(frame.Line))
+			builder.WriteString("\n")
+		}
+		if !more {
+			break
+		}
+	}
+	return builder.String()
+}
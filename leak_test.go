@@ -0,0 +1,57 @@
+package ahocorasick
+
+import (
+	. "github.com/smartystreets/goconvey/convey"
+	"runtime"
+	"testing"
+)
+
+func TestLeakTracking(t *testing.T) {
+	Convey("GIVEN leak tracking is enabled", t, func() {
+		SetLeakTracking(true)
+		Reset(func() {
+			SetLeakTracking(false)
+		})
+		before := LiveAutomata()
+
+		Convey("WHEN an automaton is created", func() {
+			automaton, err := New([]string{"foo", "bar"}, WithName("TestLeakTracking"))
+			So(err, ShouldBeNil)
+			live := LiveAutomata()
+
+			Convey("THEN it is counted with its native memory and creation stack", func() {
+				So(live.Count, ShouldEqual, before.Count+1)
+				So(live.NativeBytes, ShouldEqual, before.NativeBytes+uint64(automaton.MemoryUsage()))
+				tracked := live.Tracked[len(live.Tracked)-1]
+				So(tracked.Name, ShouldEqual, "TestLeakTracking")
+				So(tracked.Stack, ShouldContainSubstring, "leak_test.go")
+			})
+
+			Convey("THEN closing it releases it", func() {
+				So(automaton.Close(), ShouldBeNil)
+				So(automaton.Close(), ShouldBeNil)
+				So(LiveAutomata().Count, ShouldEqual, before.Count)
+				So(func() { automaton.IsMatch("foo") }, ShouldPanicWith, ErrClosed)
+			})
+		})
+
+		Convey("WHEN an automaton is garbage collected without being closed", func() {
+			func() {
+				automaton, err := New([]string{"foo", "bar"}, WithName("TestLeakTracking_leaked"))
+				So(err, ShouldBeNil)
+				So(automaton.IsMatch("foo"), ShouldBeTrue)
+			}()
+			var names []string
+			for i := 0; i < 10; i++ {
+				runtime.GC()
+				for _, leaked := range LeakedAutomata() {
+					names = append(names, leaked.Name)
+				}
+			}
+
+			Convey("THEN it is reported as leaked", func() {
+				So(names, ShouldContain, "TestLeakTracking_leaked")
+			})
+		})
+	})
+}
@@ -1,5 +1,7 @@
 package ahocorasick
 
+import "fmt"
+
 // MatchKind is a knob for controlling the match semantics of an Aho-Corasick automaton.
 //
 // There are two generally different ways that Aho-Corasick automatons can report matches. The first way is the
@@ -71,3 +73,36 @@ const (
 	//This does not support overlapping matches or stream searching. If this match kind is used, attempting to find overlapping matches or stream matches will fail.
 	MatchKindLeftMostFirst MatchKind = 3
 )
+
+// String returns the textual name of the match kind, e.g. "leftmost-longest".
+func (k MatchKind) String() string {
+	switch k {
+	case MatchKindStandard:
+		return "standard"
+	case MatchKindLeftMostLongest:
+		return "leftmost-longest"
+	case MatchKindLeftMostFirst:
+		return "leftmost-first"
+	}
+	return fmt.Sprintf("MatchKind(%d)", int(k))
+}
+
+// MarshalText implements [encoding.TextMarshaler] using the names returned by [MatchKind.String].
+func (k MatchKind) MarshalText() ([]byte, error) {
+	switch k {
+	case MatchKindStandard, MatchKindLeftMostLongest, MatchKindLeftMostFirst:
+		return []byte(k.String()), nil
+	}
+	return nil, fmt.Errorf("ahocorasick: invalid match kind %d", int(k))
+}
+
+// UnmarshalText implements [encoding.TextUnmarshaler]. It accepts the names returned by [MatchKind.String].
+func (k *MatchKind) UnmarshalText(text []byte) error {
+	for _, kind := range []MatchKind{MatchKindStandard, MatchKindLeftMostLongest, MatchKindLeftMostFirst} {
+		if string(text) == kind.String() {
+			*k = kind
+			return nil
+		}
+	}
+	return fmt.Errorf("ahocorasick: unknown match kind %q", text)
+}
@@ -0,0 +1,112 @@
+package ahocorasick
+
+import (
+	"context"
+	"runtime/trace"
+	"time"
+)
+
+// SearchMethod identifies the [AhoCorasick] or [DynamicMatcher] method that performed a search.
+type SearchMethod string
+
+const (
+	SearchMethodCount           SearchMethod = "Count"           // A search performed by [AhoCorasick.Count] or one of its variants.
+	SearchMethodFindAll         SearchMethod = "FindAll"         // A search performed by [AhoCorasick.FindAll].
+	SearchMethodFindFirst       SearchMethod = "FindFirst"       // A search performed by [AhoCorasick.FindFirst].
+	SearchMethodFindLast        SearchMethod = "FindLast"        // A search performed by [AhoCorasick.FindLast].
+	SearchMethodFindN           SearchMethod = "FindN"           // A search performed by [AhoCorasick.FindN].
+	SearchMethodFindOverlapping SearchMethod = "FindOverlapping" // A search performed by [AhoCorasick.FindOverlapping].
+	SearchMethodIsMatch         SearchMethod = "IsMatch"         // A search performed by [AhoCorasick.IsMatch].
+	SearchMethodPrefix          SearchMethod = "Prefix"          // A search performed by [AhoCorasick.AllPrefixes] or [AhoCorasick.LongestPrefix].
+	SearchMethodWhichMatch      SearchMethod = "WhichMatch"      // A search performed by [AhoCorasick.WhichMatch].
+)
+
+// SearchEvent describes a single search performed by an [AhoCorasick] automaton or a [DynamicMatcher].
+type SearchEvent struct {
+	// The method that performed the search.
+	Method SearchMethod
+	// The length of the searched haystack in bytes.
+	HaystackLen int
+	// The number of matches reported to the caller.
+	MatchCount int
+	// The wall-clock time spent in the search, including the cost of crossing the FFI boundary.
+	Duration time.Duration
+	// Whether the search stopped before reaching the end of the haystack, e.g. because [AhoCorasick.IsMatch]
+	// or [AhoCorasick.FindFirst] found a match, or [AhoCorasick.FindN] reached its limit.
+	//
+	// Prefix lookups ([SearchMethodPrefix]) are the exception: their anchored search stops as soon as no pattern
+	// can match anymore, often before the end of the haystack, but the native library does not report where.
+	// For them, ShortCircuited reports whether a prefix was found instead.
+	ShortCircuited bool
+}
+
+// Observer receives an event for every search performed by an [AhoCorasick] automaton.
+//
+// Observers are called synchronously on the goroutine that performed the search, after the search finished,
+// so they should be cheap and must be safe for concurrent use if the automaton is shared between goroutines.
+//
+// An observer can be set on an [AhoCorasickBuilder] using [AhoCorasickBuilder.SetObserver], in which case every
+// automaton built by it is observed, or on an individual automaton using [AhoCorasick.SetObserver].
+// A [DynamicMatcher] reports one event per search to the observer of the builder it was created with, regardless
+// of how many of its internal automatons the search used.
+type Observer interface {
+	ObserveSearch(event SearchEvent)
+}
+
+// ObserverFunc is an adapter to allow the use of ordinary functions as an [Observer].
+type ObserverFunc func(event SearchEvent)
+
+// ObserveSearch calls f(event).
+func (f ObserverFunc) ObserveSearch(event SearchEvent) {
+	f(event)
+}
+
+// searchSpan measures a single search, updating the automaton's counters and reporting the search to its
+// [Observer] and to runtime/trace once it is finished.
+// The clock is only read if there is an observer, so unobserved searches stay cheap.
+type searchSpan struct {
+	// nil if the searcher keeps no counters.
+	counters *searchCounters
+	method   SearchMethod
+	observer Observer
+	region   *trace.Region
+	start    time.Time
+}
+
+// beginSearch starts measuring a search performed by ac using method.
+func (ac *AhoCorasick) beginSearch(method SearchMethod) searchSpan {
+	return beginSearch(ac.counters, ac.name, ac.GetObserver(), method)
+}
+
+// beginSearch starts measuring a search performed using method by a searcher with the given counters, which may be
+// nil, name and observer.
+func beginSearch(counters *searchCounters, name string, observer Observer, method SearchMethod) searchSpan {
+	span := searchSpan{counters: counters, method: method, observer: observer}
+	if trace.IsEnabled() {
+		span.region = trace.StartRegion(context.Background(), traceRegionType(name, method))
+	}
+	if span.observer != nil {
+		span.start = time.Now()
+	}
+	return span
+}
+
+// end finishes the measured search.
+func (s searchSpan) end(haystackLen int, matchCount int, shortCircuited bool) {
+	if s.region != nil {
+		s.region.End()
+	}
+	if s.counters != nil {
+		s.counters.record(haystackLen, matchCount)
+	}
+	if s.observer == nil {
+		return
+	}
+	s.observer.ObserveSearch(SearchEvent{
+		Method:         s.method,
+		HaystackLen:    haystackLen,
+		MatchCount:     matchCount,
+		Duration:       time.Since(s.start),
+		ShortCircuited: shortCircuited,
+	})
+}
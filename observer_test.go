@@ -0,0 +1,51 @@
+package ahocorasick
+
+import (
+	"fmt"
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+func ExampleObserverFunc() {
+	observer := ObserverFunc(func(event SearchEvent) {
+		fmt.Println(event.Method, event.HaystackLen, event.MatchCount, event.ShortCircuited)
+	})
+	automaton := NewAhoCorasickBuilder().SetObserver(observer).Build([]string{"foo", "bar"})
+	automaton.FindAll("foo bar foo")
+	automaton.IsMatch("xxx bar")
+	// Output:
+	// FindAll 11 3 false
+	// IsMatch 7 1 true
+}
+
+func TestObserver(t *testing.T) {
+	Convey("GIVEN an automaton built with an observer", t, func() {
+		var events []SearchEvent
+		observer := ObserverFunc(func(event SearchEvent) {
+			events = append(events, event)
+		})
+		automaton, err := New([]string{"foo", "bar"}, WithObserver(observer))
+		So(err, ShouldBeNil)
+
+		Convey("WHEN FindFirst does not find a match", func() {
+			automaton.FindFirst("quux")
+
+			Convey("THEN the whole haystack was scanned", func() {
+				So(events, ShouldHaveLength, 1)
+				So(events[0].Method, ShouldEqual, SearchMethodFindFirst)
+				So(events[0].HaystackLen, ShouldEqual, 4)
+				So(events[0].MatchCount, ShouldEqual, 0)
+				So(events[0].ShortCircuited, ShouldBeFalse)
+			})
+		})
+
+		Convey("WHEN the observer is removed from the automaton", func() {
+			automaton.SetObserver(nil)
+			automaton.FindAll("foo")
+
+			Convey("THEN no events are reported", func() {
+				So(events, ShouldBeEmpty)
+			})
+		})
+	})
+}
@@ -0,0 +1,93 @@
+package ahocorasick
+
+import (
+	"fmt"
+	"sort"
+	"unicode/utf8"
+)
+
+// LSPPosition is a position in a text document as defined by the Language Server Protocol: a zero-based line and
+// a zero-based character offset in that line, counted in UTF-16 code units.
+//
+// Lines are terminated by "\n", "\r\n" or "\r", as required by the protocol.
+type LSPPosition struct {
+	Line      uint `json:"line"`
+	Character uint `json:"character"`
+}
+
+// ConvertedMatch is a match whose byte offsets were converted by [ConvertOffsets].
+type ConvertedMatch struct {
+	// The match, with its byte offsets.
+	Match Match
+	// The offsets of the match counted in runes.
+	RuneStart uint
+	RuneEnd   uint
+	// The offsets of the match counted in UTF-16 code units.
+	UTF16Start uint
+	UTF16End   uint
+	// The start and end of the match as LSP positions.
+	LSPStart LSPPosition
+	LSPEnd   LSPPosition
+}
+
+// ConvertOffsets converts the byte offsets of the matches to rune offsets, UTF-16 offsets and LSP positions,
+// in a single pass over the haystack.
+//
+// The matches are converted in the given order, which may be any order, but sorting the matches by their start
+// (as all searches do) is cheapest. Invalid UTF-8 bytes count as one rune each, encoded as one UTF-16 code unit like
+// [utf8.RuneError]. An offset in the middle of a rune, which a match of a pattern that is not valid UTF-8 may have,
+// is converted as if it was at the start of that rune. ConvertOffsets panics with an error wrapping [ErrInvalidSpan]
+// if a match ends after the end of the haystack.
+func ConvertOffsets(input string, matches []Match) []ConvertedMatch {
+	result := make([]ConvertedMatch, len(matches))
+	// Every match has two offsets to convert: its start at index 2*i and its end at index 2*i+1.
+	offsets := make([]int, 2*len(matches))
+	for i, match := range matches {
+		if match.Start > match.End || match.End > uint(len(input)) {
+			panic(fmt.Errorf("%w: match [%d, %d) is not within a haystack of length %d", ErrInvalidSpan, match.Start, match.End, len(input)))
+		}
+		result[i].Match = match
+		offsets[2*i], offsets[2*i+1] = 2*i, 2*i+1
+	}
+	offsetOf := func(index int) uint {
+		if index%2 == 0 {
+			return matches[index/2].Start
+		}
+		return matches[index/2].End
+	}
+	if !sort.SliceIsSorted(offsets, func(i, j int) bool { return offsetOf(offsets[i]) < offsetOf(offsets[j]) }) {
+		sort.SliceStable(offsets, func(i, j int) bool {
+			return offsetOf(offsets[i]) < offsetOf(offsets[j])
+		})
+	}
+
+	var runes, utf16Units, line, lineStart uint
+	next := 0
+	convert := func(limit uint) {
+		for ; next < len(offsets) && offsetOf(offsets[next]) < limit; next++ {
+			converted := &result[offsets[next]/2]
+			position := LSPPosition{Line: line, Character: utf16Units - lineStart}
+			if offsets[next]%2 == 0 {
+				converted.RuneStart, converted.UTF16Start, converted.LSPStart = runes, utf16Units, position
+			} else {
+				converted.RuneEnd, converted.UTF16End, converted.LSPEnd = runes, utf16Units, position
+			}
+		}
+	}
+	for i := 0; i < len(input) && next < len(offsets); {
+		r, size := utf8.DecodeRuneInString(input[i:])
+		convert(uint(i + size))
+		runes++
+		utf16Units++
+		if r >= 0x10000 {
+			utf16Units++
+		}
+		if r == '\n' || r == '\r' && (i+1 == len(input) || input[i+1] != '\n') {
+			line++
+			lineStart = utf16Units
+		}
+		i += size
+	}
+	convert(uint(len(input)) + 1)
+	return result
+}
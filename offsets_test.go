@@ -0,0 +1,87 @@
+package ahocorasick
+
+import (
+	"fmt"
+	. "github.com/smartystreets/goconvey/convey"
+	"math/rand"
+	"strings"
+	"testing"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+func ExampleConvertOffsets() {
+	haystack := "let s = \"😀 TODO\"\nTODO"
+	automaton := NewAhoCorasick([]string{"TODO"})
+	for _, converted := range ConvertOffsets(haystack, automaton.FindAll(haystack)) {
+		fmt.Println(converted.Match.Start, converted.RuneStart, converted.UTF16Start, converted.LSPStart)
+	}
+	// Output:
+	// 14 11 12 {0 12}
+	// 20 17 18 {1 0}
+}
+
+// naiveConversion converts the byte offset of a valid UTF-8 haystack by rescanning its prefix.
+func naiveConversion(haystack string, offset uint) (uint, uint, LSPPosition) {
+	prefix := []rune(haystack[:offset])
+	units := uint(len(utf16.Encode(prefix)))
+	normalized := strings.ReplaceAll(strings.ReplaceAll(haystack[:offset], "\r\n", "\n"), "\r", "\n")
+	// A "\r" right before the offset may be followed by a "\n" in the rest of the haystack.
+	if strings.HasSuffix(haystack[:offset], "\r") && strings.HasPrefix(haystack[offset:], "\n") {
+		normalized = normalized[:len(normalized)-1] + "\r"
+	}
+	lastLine := normalized[strings.LastIndexByte(normalized, '\n')+1:]
+	return uint(len(prefix)), units, LSPPosition{
+		Line:      uint(strings.Count(normalized, "\n")),
+		Character: uint(len(utf16.Encode([]rune(lastLine)))),
+	}
+}
+
+func TestConvertOffsets(t *testing.T) {
+	Convey("GIVEN random haystacks with line terminators and runes of every UTF-8 length", t, func() {
+		random := rand.New(rand.NewSource(1))
+		pieces := []string{"a", "é", "€", "😀", "\n", "\r\n", "\r"}
+
+		Convey("THEN the converted offsets agree with a rescan of the haystack", func() {
+			for i := 0; i < 300; i++ {
+				var builder strings.Builder
+				for j := random.Intn(20); j > 0; j-- {
+					builder.WriteString(pieces[random.Intn(len(pieces))])
+				}
+				haystack := builder.String()
+				var matches []Match
+				for _, match := range NewAhoCorasick([]string{"a", "é😀", "\r", "\n", ""}).FindOverlapping(haystack) {
+					// The empty pattern also matches in the middle of runes.
+					if match.Start == uint(len(haystack)) || utf8.RuneStart(haystack[match.Start]) {
+						matches = append(matches, match)
+					}
+				}
+				random.Shuffle(len(matches), func(i, j int) {
+					matches[i], matches[j] = matches[j], matches[i]
+				})
+				for j, converted := range ConvertOffsets(haystack, matches) {
+					So(converted.Match, ShouldResemble, matches[j])
+					runeStart, utf16Start, lspStart := naiveConversion(haystack, matches[j].Start)
+					runeEnd, utf16End, lspEnd := naiveConversion(haystack, matches[j].End)
+					So(converted, ShouldResemble, ConvertedMatch{
+						Match:      matches[j],
+						RuneStart:  runeStart,
+						RuneEnd:    runeEnd,
+						UTF16Start: utf16Start,
+						UTF16End:   utf16End,
+						LSPStart:   lspStart,
+						LSPEnd:     lspEnd,
+					})
+				}
+			}
+		})
+	})
+	Convey("GIVEN a match starting in the middle of a rune", t, func() {
+		converted := ConvertOffsets("aé", []Match{{End: 3, PatternIndex: 0, Start: 2}})
+
+		Convey("THEN its start is converted as if it was at the start of the rune", func() {
+			So(converted[0].RuneStart, ShouldEqual, 1)
+			So(converted[0].RuneEnd, ShouldEqual, 2)
+		})
+	})
+}
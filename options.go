@@ -0,0 +1,96 @@
+package ahocorasick
+
+// Option configures an automaton created by [New].
+//
+// Each option corresponds to one of the setters of [AhoCorasickBuilder], and [New] builds the automaton through
+// an [AhoCorasickBuilder], so both ways of constructing an automaton are validated and built identically.
+type Option func(b *AhoCorasickBuilder)
+
+// New creates an [AhoCorasick] automaton for the given patterns, starting from the default configuration
+// and applying opts in order.
+//
+// An error is returned under the same conditions as [AhoCorasickBuilder.TryBuild].
+func New(patterns []string, opts ...Option) (*AhoCorasick, error) {
+	builder := NewAhoCorasickBuilder()
+	for _, opt := range opts {
+		opt(builder)
+	}
+	return builder.TryBuild(patterns)
+}
+
+// WithAsciiCaseInsensitive is the [Option] equivalent of [AhoCorasickBuilder.SetAsciiCaseInsensitive].
+func WithAsciiCaseInsensitive(asciiCaseInsensitive bool) Option {
+	return func(b *AhoCorasickBuilder) {
+		b.SetAsciiCaseInsensitive(asciiCaseInsensitive)
+	}
+}
+
+// WithByteClasses is the [Option] equivalent of [AhoCorasickBuilder.SetByteClasses].
+func WithByteClasses(byteClasses bool) Option {
+	return func(b *AhoCorasickBuilder) {
+		b.SetByteClasses(byteClasses)
+	}
+}
+
+// WithConfig replaces all settings described by [Config] with the ones in config. Options following it still apply.
+func WithConfig(config Config) Option {
+	return func(b *AhoCorasickBuilder) {
+		b.applyConfig(config)
+	}
+}
+
+// WithDenseDepth is the [Option] equivalent of [AhoCorasickBuilder.SetDenseDepth].
+func WithDenseDepth(denseDepth uint) Option {
+	return func(b *AhoCorasickBuilder) {
+		b.SetDenseDepth(&denseDepth)
+	}
+}
+
+// WithKind is the [Option] equivalent of [AhoCorasickBuilder.SetKind].
+func WithKind(kind AhoCorasickKind) Option {
+	return func(b *AhoCorasickBuilder) {
+		b.SetKind(&kind)
+	}
+}
+
+// WithMatchKind is the [Option] equivalent of [AhoCorasickBuilder.SetMatchKind].
+func WithMatchKind(matchKind MatchKind) Option {
+	return func(b *AhoCorasickBuilder) {
+		b.SetMatchKind(matchKind)
+	}
+}
+
+// WithName is the [Option] equivalent of [AhoCorasickBuilder.SetName].
+func WithName(name string) Option {
+	return func(b *AhoCorasickBuilder) {
+		b.SetName(name)
+	}
+}
+
+// WithObserver is the [Option] equivalent of [AhoCorasickBuilder.SetObserver].
+func WithObserver(observer Observer) Option {
+	return func(b *AhoCorasickBuilder) {
+		b.SetObserver(observer)
+	}
+}
+
+// WithPrefilter is the [Option] equivalent of [AhoCorasickBuilder.SetPrefilter].
+func WithPrefilter(prefilter bool) Option {
+	return func(b *AhoCorasickBuilder) {
+		b.SetPrefilter(prefilter)
+	}
+}
+
+// WithStartKind is the [Option] equivalent of [AhoCorasickBuilder.SetStartKind].
+func WithStartKind(startKind StartKind) Option {
+	return func(b *AhoCorasickBuilder) {
+		b.SetStartKind(startKind)
+	}
+}
+
+// WithUTF8Aligned is the [Option] equivalent of [AhoCorasickBuilder.SetUTF8Aligned].
+func WithUTF8Aligned(utf8Aligned bool) Option {
+	return func(b *AhoCorasickBuilder) {
+		b.SetUTF8Aligned(utf8Aligned)
+	}
+}
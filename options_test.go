@@ -0,0 +1,61 @@
+package ahocorasick
+
+import (
+	"fmt"
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+func ExampleNew() {
+	automaton, err := New([]string{"Samwise", "Sam"}, WithMatchKind(MatchKindLeftMostFirst), WithKind(AhoCorasickKindDFA))
+	if err != nil {
+		panic(err)
+	}
+	haystack := "Samwise"
+	match := automaton.FindFirst(haystack)
+	fmt.Println(haystack[match.Start:match.End], automaton.GetKind())
+	// Output: Samwise dfa
+}
+
+func TestNew(t *testing.T) {
+	Convey("GIVEN a set of patterns", t, func() {
+		patterns := []string{"append", "appendage", "app"}
+		haystack := "append the app to the appendage"
+
+		Convey("WHEN an automaton is created with options", func() {
+			automaton, err := New(patterns, WithMatchKind(MatchKindLeftMostLongest), WithDenseDepth(3), WithKind(AhoCorasickKindContinuousNFA))
+
+			Convey("THEN it matches the automaton built with the equivalent builder", func() {
+				denseDepth := uint(3)
+				kind := AhoCorasickKindContinuousNFA
+				expected := NewAhoCorasickBuilder().SetMatchKind(MatchKindLeftMostLongest).SetDenseDepth(&denseDepth).SetKind(&kind).Build(patterns)
+				So(err, ShouldBeNil)
+				So(automaton.GetKind(), ShouldEqual, expected.GetKind())
+				So(automaton.FindAll(haystack), ShouldResemble, expected.FindAll(haystack))
+			})
+		})
+
+		Convey("WHEN an option is out of range", func() {
+			automaton, err := New(patterns, WithMatchKind(MatchKind(42)))
+
+			Convey("THEN an error is returned", func() {
+				So(automaton, ShouldBeNil)
+				So(err, ShouldNotBeNil)
+			})
+
+			Convey("THEN the builder reports the same error", func() {
+				_, builderErr := NewAhoCorasickBuilder().SetMatchKind(MatchKind(42)).TryBuild(patterns)
+				So(builderErr, ShouldResemble, err)
+			})
+		})
+	})
+
+	Convey("GIVEN an empty list of patterns", t, func() {
+		automaton, err := New(nil)
+
+		Convey("THEN the automaton never matches", func() {
+			So(err, ShouldBeNil)
+			So(automaton.IsMatch("anything"), ShouldBeFalse)
+		})
+	})
+}
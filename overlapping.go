@@ -0,0 +1,174 @@
+package ahocorasick
+
+/*
+#include "./ahocorasick_rs.h"
+*/
+import "C"
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"sort"
+	"unsafe"
+)
+
+// ErrUnsupportedSearch is wrapped by the panic value used when a search is not supported by the configuration
+// of an automaton, e.g. an overlapping search on an automaton using leftmost match semantics.
+var ErrUnsupportedSearch = errors.New("ahocorasick: search not supported by the automaton's configuration")
+
+// FindOverlapping returns all overlapping matches in the haystack, i.e. every occurrence of every pattern,
+// ordered by their ending position.
+//
+// Overlapping searches are only supported by automatons using [MatchKindStandard] that support unanchored searches.
+// FindOverlapping panics with an error wrapping [ErrUnsupportedSearch] otherwise.
+func (ac *AhoCorasick) FindOverlapping(input string) []Match {
+	ac.requireOverlapping()
+	span := ac.beginSearch(SearchMethodFindOverlapping)
+	result := ac.findOverlappingIn(input, 0, len(input))
+	if ac.config.UTF8Aligned {
+		result = filterAligned(result, alignedIn(input))
+	}
+	span.end(len(input), len(result), false)
+	return result
+}
+
+// findOverlappingIn returns the overlapping matches in input[start:end], including those that are not aligned,
+// without measuring the search. ac must support overlapping searches, see [AhoCorasick.overlapping].
+func (ac *AhoCorasick) findOverlappingIn(input string, start int, end int) []Match {
+	haystack := input[start:end]
+	cText := (*C.char)(unsafe.Pointer(unsafe.StringData(haystack)))
+	foundCount := C.long(0)
+	cErr := C.int(0)
+	cMatches := C.find_overlapping_iter(ac.native(), cText, C.size_t(len(haystack)), &foundCount, &cErr)
+	runtime.KeepAlive(cText)
+	runtime.KeepAlive(haystack)
+	runtime.KeepAlive(ac)
+	checkNativeSearch(cErr)
+	matches := takeCMatches(cMatches, foundCount)
+	for i := range matches {
+		matches[i].Start += uint(start)
+		matches[i].End += uint(start)
+	}
+	return matches
+}
+
+// requireOverlapping panics unless ac supports overlapping searches.
+func (ac *AhoCorasick) requireOverlapping() {
+	if ac.config.MatchKind != MatchKindStandard {
+		panic(fmt.Errorf("%w: overlapping searches require %v match semantics, not %v", ErrUnsupportedSearch, MatchKindStandard, ac.config.MatchKind))
+	}
+	if ac.config.StartKind == StartKindAnchored {
+		panic(fmt.Errorf("%w: overlapping searches require unanchored searches to be supported", ErrUnsupportedSearch))
+	}
+}
+
+// overlapping returns an automaton for the same patterns and settings as ac that supports overlapping searches:
+// ac itself if possible, otherwise a companion automaton using [MatchKindStandard] that is built on first use.
+func (ac *AhoCorasick) overlapping() *AhoCorasick {
+	if ac.config.MatchKind == MatchKindStandard && ac.config.StartKind != StartKindAnchored {
+		return ac
+	}
+	ac.companionMu.Lock()
+	defer ac.companionMu.Unlock()
+	if ac.companion == nil {
+		builder := NewAhoCorasickBuilderFromConfig(ac.config).
+			SetMatchKind(MatchKindStandard).
+			SetStartKind(StartKindUnanchored).
+			SetUTF8Aligned(false)
+		ac.companion = builder.Build(ac.patterns)
+	}
+	return ac.companion
+}
+
+// unanchoredOverlapping is like [AhoCorasick.overlapping], but panics with an error wrapping [ErrUnsupportedSearch]
+// if ac only supports anchored searches, since method would otherwise report matches that ac cannot find.
+func (ac *AhoCorasick) unanchoredOverlapping(method string) *AhoCorasick {
+	ac.requireUnanchored(method)
+	return ac.overlapping()
+}
+
+// checkNativeSearch panics with an error wrapping [ErrUnsupportedSearch] if the native library reported through cErr
+// that the automaton does not support a search. Callers are expected to reject such searches beforehand, so this is
+// a safety net rather than the usual way these errors are reported.
+func checkNativeSearch(cErr C.int) {
+	if cErr != 0 {
+		panic(fmt.Errorf("%w: rejected by the native library", ErrUnsupportedSearch))
+	}
+}
+
+// requireUnanchored panics with an error wrapping [ErrUnsupportedSearch] if ac only supports anchored searches,
+// which method needs.
+func (ac *AhoCorasick) requireUnanchored(method string) {
+	if ac.config.StartKind == StartKindAnchored {
+		panic(fmt.Errorf("%w: %s requires unanchored searches to be supported", ErrUnsupportedSearch, method))
+	}
+}
+
+// selectNonOverlapping picks the matches that a non-overlapping search using matchKind semantics would report,
+// given every candidate match of every pattern (as reported by [AhoCorasick.FindOverlapping]).
+// The PatternIndex of a candidate doubles as its priority for [MatchKindLeftMostFirst]: lower indexes win.
+// At most limit matches are returned, unless limit is negative. candidates is reordered in place.
+//
+// This mirrors the way the native library iterates over non-overlapping matches, including the handling of
+// empty matches, so that searches which need to discard some candidates (e.g. disabled patterns) still
+// report the same matches as an automaton built without those candidates would. The one exception is the empty
+// pattern under leftmost semantics, which is selected like an empty regex alternative would be, whereas the native
+// automaton may prefer a later non-empty match.
+func selectNonOverlapping(candidates []Match, matchKind MatchKind, limit int) []Match {
+	sortCandidates(candidates, matchKind)
+	var result []Match
+	pos := uint(0)
+	lastEnd, hasLastEnd := uint(0), false
+	for i := 0; i < len(candidates) && limit != 0; {
+		candidate := candidates[i]
+		if candidate.Start < pos {
+			i++
+			continue
+		}
+		if candidate.Start == candidate.End && hasLastEnd && candidate.End == lastEnd {
+			// An empty match right after the previous match is skipped by restarting one byte later.
+			pos++
+			continue
+		}
+		result = append(result, candidate)
+		pos = candidate.End
+		lastEnd, hasLastEnd = candidate.End, true
+		limit--
+		if candidate.Start != candidate.End {
+			i++
+		}
+		// An empty match is the first candidate of the next search as well, where it is skipped as above.
+	}
+	return result
+}
+
+// sortCandidates orders candidates so that, among the candidates starting at or after any given position,
+// the first one is the match preferred by matchKind semantics.
+func sortCandidates(candidates []Match, matchKind MatchKind) {
+	sort.Slice(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		switch matchKind {
+		case MatchKindLeftMostFirst:
+			if a.Start != b.Start {
+				return a.Start < b.Start
+			}
+		case MatchKindLeftMostLongest:
+			if a.Start != b.Start {
+				return a.Start < b.Start
+			}
+			if a.End != b.End {
+				return a.End > b.End
+			}
+		default:
+			// Standard semantics report the match that is detected first, i.e. the one ending first.
+			// Among matches ending at the same position, the longest one is detected first.
+			if a.End != b.End {
+				return a.End < b.End
+			}
+			if a.Start != b.Start {
+				return a.Start < b.Start
+			}
+		}
+		return a.PatternIndex < b.PatternIndex
+	})
+}
@@ -0,0 +1,79 @@
+package ahocorasick
+
+import (
+	"errors"
+	"fmt"
+	. "github.com/smartystreets/goconvey/convey"
+	"math/rand"
+	"testing"
+)
+
+func ExampleAhoCorasick_FindOverlapping() {
+	automaton := NewAhoCorasick([]string{"append", "appendage", "app"})
+	haystack := "append the appendage"
+	for _, match := range automaton.FindOverlapping(haystack) {
+		fmt.Println(match.PatternIndex, haystack[match.Start:match.End])
+	}
+	// Output:
+	// 2 app
+	// 0 append
+	// 2 app
+	// 0 append
+	// 1 appendage
+}
+
+// randomPatterns returns count random strings over a small alphabet, so that they overlap a lot.
+func randomPatterns(random *rand.Rand, count int, maxLen int) []string {
+	patterns := make([]string, count)
+	for i := range patterns {
+		pattern := make([]byte, random.Intn(maxLen+1))
+		for j := range pattern {
+			pattern[j] = "abcA"[random.Intn(4)]
+		}
+		patterns[i] = string(pattern)
+	}
+	return patterns
+}
+
+func TestSelectNonOverlapping(t *testing.T) {
+	Convey("GIVEN random patterns and haystacks", t, func() {
+		random := rand.New(rand.NewSource(1))
+
+		Convey("THEN selecting from overlapping matches agrees with the native non-overlapping search", func() {
+			for _, matchKind := range []MatchKind{MatchKindStandard, MatchKindLeftMostFirst, MatchKindLeftMostLongest} {
+				for _, caseInsensitive := range []bool{false, true} {
+					for i := 0; i < 200; i++ {
+						patterns := randomPatterns(random, 1+random.Intn(6), 4)
+						if matchKind != MatchKindStandard {
+							// Empty patterns under leftmost semantics are a documented exception.
+							for j := range patterns {
+								patterns[j] += "b"
+							}
+						}
+						haystack := randomPatterns(random, 1, 30)[0]
+						builder := NewAhoCorasickBuilder().SetAsciiCaseInsensitive(caseInsensitive)
+						overlapping := builder.Clone().Build(patterns)
+						expected := builder.SetMatchKind(matchKind).Build(patterns).FindAll(haystack)
+						actual := selectNonOverlapping(overlapping.FindOverlapping(haystack), matchKind, -1)
+						if len(expected) == 0 {
+							So(actual, ShouldBeEmpty)
+						} else {
+							So(actual, ShouldResemble, expected)
+						}
+					}
+				}
+			}
+		})
+	})
+
+	Convey("GIVEN an automaton using leftmost semantics", t, func() {
+		automaton := NewAhoCorasickBuilder().SetMatchKind(MatchKindLeftMostFirst).Build([]string{"foo"})
+
+		Convey("THEN overlapping searches panic", func() {
+			defer func() {
+				So(errors.Is(recover().(error), ErrUnsupportedSearch), ShouldBeTrue)
+			}()
+			automaton.FindOverlapping("foo")
+		})
+	})
+}
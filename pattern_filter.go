@@ -0,0 +1,121 @@
+package ahocorasick
+
+// FindAllFiltered is like [AhoCorasick.FindAll], but only reports matches of the patterns in allowed.
+// A nil set allows every pattern.
+//
+// Disabled patterns are ignored as if the automaton had been built without them: under leftmost semantics,
+// a disabled pattern does not shadow an enabled one matching at the same position.
+//
+// When none of the matches found by [AhoCorasick.FindAll] belongs to a disabled pattern, the search costs the same
+// as FindAll. Otherwise, the matches are selected from all overlapping matches, using a companion automaton with
+// [MatchKindStandard] semantics if this automaton uses leftmost semantics. The companion automaton is built on
+// first use and kept for the lifetime of this automaton.
+//
+// Observers see a single [SearchMethodFindAll] search, whichever path was taken.
+func (ac *AhoCorasick) FindAllFiltered(input string, allowed *PatternSet) []Match {
+	ac.requireUnanchored("FindAllFiltered")
+	span := ac.beginSearch(SearchMethodFindAll)
+	matches := ac.findAllIn(input, 0, len(input))
+	if allowed != nil && !allAllowed(matches, allowed) {
+		matches = selectNonOverlapping(ac.filteredCandidates(input, allowed), ac.config.MatchKind, -1)
+	}
+	span.end(len(input), len(matches), false)
+	return matches
+}
+
+// FindFirstFiltered is like [AhoCorasick.FindFirst], but only reports matches of the patterns in allowed.
+// A nil set allows every pattern. See [AhoCorasick.FindAllFiltered] for details.
+func (ac *AhoCorasick) FindFirstFiltered(input string, allowed *PatternSet) *Match {
+	ac.requireUnanchored("FindFirstFiltered")
+	span := ac.beginSearch(SearchMethodFindFirst)
+	match := ac.findFirstIn(input, 0, len(input))
+	shortCircuited := match != nil
+	if match != nil && allowed != nil && !allowed.Contains(match.PatternIndex) {
+		match = firstOf(selectNonOverlapping(ac.filteredCandidates(input, allowed), ac.config.MatchKind, 1))
+		shortCircuited = false
+	}
+	if match == nil {
+		span.end(len(input), 0, false)
+		return nil
+	}
+	span.end(len(input), 1, shortCircuited)
+	return match
+}
+
+// IsMatchFiltered is like [AhoCorasick.IsMatch], but only considers the patterns in allowed.
+// A nil set allows every pattern. See [AhoCorasick.FindAllFiltered] for details.
+func (ac *AhoCorasick) IsMatchFiltered(input string, allowed *PatternSet) bool {
+	ac.requireUnanchored("IsMatchFiltered")
+	span := ac.beginSearch(SearchMethodIsMatch)
+	found := ac.isMatchIn(input, 0, len(input))
+	shortCircuited := found
+	if found && allowed != nil {
+		found = len(ac.filteredCandidates(input, allowed)) > 0
+		shortCircuited = false
+	}
+	matchCount := 0
+	if found {
+		matchCount = 1
+	}
+	span.end(len(input), matchCount, shortCircuited)
+	return found
+}
+
+// filteredCandidates returns the overlapping matches of the patterns in allowed.
+func (ac *AhoCorasick) filteredCandidates(input string, allowed *PatternSet) []Match {
+	candidates := ac.overlapping().findOverlappingIn(input, 0, len(input))
+	if ac.config.UTF8Aligned {
+		candidates = filterAligned(candidates, alignedIn(input))
+	}
+	filtered := candidates[:0]
+	for _, candidate := range candidates {
+		if allowed.Contains(candidate.PatternIndex) {
+			filtered = append(filtered, candidate)
+		}
+	}
+	return filtered
+}
+
+func allAllowed(matches []Match, allowed *PatternSet) bool {
+	for _, match := range matches {
+		if !allowed.Contains(match.PatternIndex) {
+			return false
+		}
+	}
+	return true
+}
+
+// FilteredAutomaton is a view of an [AhoCorasick] automaton that only reports matches of a fixed set of patterns.
+//
+// Views are cheap to create, so a single automaton can be shared by many views enabling different patterns,
+// e.g. one per tenant. A view is safe for concurrent use if the underlying automaton is.
+type FilteredAutomaton struct {
+	automaton *AhoCorasick
+	allowed   *PatternSet
+}
+
+// WithPatternFilter returns a view of this automaton that only reports matches of the patterns in allowed.
+// The set is copied, so later changes to allowed do not affect the view. A nil set allows every pattern.
+func (ac *AhoCorasick) WithPatternFilter(allowed *PatternSet) *FilteredAutomaton {
+	return &FilteredAutomaton{automaton: ac, allowed: allowed.Clone()}
+}
+
+// Allowed returns a copy of the set of patterns enabled in this view, or nil if every pattern is enabled.
+func (f *FilteredAutomaton) Allowed() *PatternSet {
+	return f.allowed.Clone()
+}
+
+// FindAll is like [AhoCorasick.FindAllFiltered] using the patterns enabled in this view.
+func (f *FilteredAutomaton) FindAll(input string) []Match {
+	return f.automaton.FindAllFiltered(input, f.allowed)
+}
+
+// FindFirst is like [AhoCorasick.FindFirstFiltered] using the patterns enabled in this view.
+func (f *FilteredAutomaton) FindFirst(input string) *Match {
+	return f.automaton.FindFirstFiltered(input, f.allowed)
+}
+
+// IsMatch is like [AhoCorasick.IsMatchFiltered] using the patterns enabled in this view.
+func (f *FilteredAutomaton) IsMatch(input string) bool {
+	return f.automaton.IsMatchFiltered(input, f.allowed)
+}
@@ -0,0 +1,135 @@
+package ahocorasick
+
+import (
+	"fmt"
+	. "github.com/smartystreets/goconvey/convey"
+	"math/rand"
+	"testing"
+)
+
+func ExampleAhoCorasick_WithPatternFilter() {
+	automaton := NewAhoCorasickBuilder().SetMatchKind(MatchKindLeftMostFirst).Build([]string{"Samwise", "Sam"})
+	haystack := "Samwise"
+	match := automaton.WithPatternFilter(NewPatternSet(1)).FindFirst(haystack)
+	fmt.Println(haystack[match.Start:match.End])
+	// Output: Sam
+}
+
+func TestPatternFilter(t *testing.T) {
+	Convey("GIVEN random patterns, haystacks and filters", t, func() {
+		random := rand.New(rand.NewSource(1))
+
+		Convey("THEN filtered searches match an automaton built from the enabled patterns only", func() {
+			for _, matchKind := range []MatchKind{MatchKindStandard, MatchKindLeftMostFirst, MatchKindLeftMostLongest} {
+				for i := 0; i < 200; i++ {
+					patterns := randomPatterns(random, 1+random.Intn(6), 3)
+					for j := range patterns {
+						patterns[j] += "a"
+					}
+					haystack := randomPatterns(random, 1, 30)[0]
+					allowed := &PatternSet{}
+					var enabled []string
+					var ids []uint
+					for id, pattern := range patterns {
+						if random.Intn(2) == 0 {
+							allowed.Add(uint(id))
+							enabled = append(enabled, pattern)
+							ids = append(ids, uint(id))
+						}
+					}
+					expected := NewAhoCorasickBuilder().SetMatchKind(matchKind).Build(enabled).FindAll(haystack)
+					for j := range expected {
+						expected[j].PatternIndex = ids[expected[j].PatternIndex]
+					}
+					view := NewAhoCorasickBuilder().SetMatchKind(matchKind).Build(patterns).WithPatternFilter(allowed)
+					actual := view.FindAll(haystack)
+					if len(expected) == 0 {
+						So(actual, ShouldBeEmpty)
+						So(view.FindFirst(haystack), ShouldBeNil)
+						So(view.IsMatch(haystack), ShouldBeFalse)
+					} else {
+						So(actual, ShouldResemble, expected)
+						So(view.FindFirst(haystack), ShouldResemble, &expected[0])
+						So(view.IsMatch(haystack), ShouldBeTrue)
+					}
+				}
+			}
+		})
+	})
+}
+
+func TestPatternFilterObserver(t *testing.T) {
+	for _, matchKind := range []MatchKind{MatchKindStandard, MatchKindLeftMostFirst} {
+		Convey(fmt.Sprintf("GIVEN an observed automaton using %v semantics", matchKind), t, func() {
+			var events []SearchEvent
+			automaton := NewAhoCorasickBuilder().
+				SetMatchKind(matchKind).
+				SetObserver(ObserverFunc(func(event SearchEvent) {
+					events = append(events, event)
+				})).
+				Build([]string{"foo", "oba", "bar"})
+			haystack := "foobar"
+
+			Convey("WHEN filtered searches need the overlapping matches", func() {
+				allowed := NewPatternSet(1, 2)
+				automaton.FindAllFiltered(haystack, allowed)
+				automaton.FindFirstFiltered(haystack, allowed)
+				automaton.IsMatchFiltered(haystack, allowed)
+
+				Convey("THEN every search is reported once", func() {
+					So(events, ShouldHaveLength, 3)
+					So(events[0].Method, ShouldEqual, SearchMethodFindAll)
+					So(events[1].Method, ShouldEqual, SearchMethodFindFirst)
+					So(events[1].ShortCircuited, ShouldBeFalse)
+					So(events[2].Method, ShouldEqual, SearchMethodIsMatch)
+					So(automaton.Stats().Searches, ShouldEqual, 3)
+					So(automaton.Stats().BytesScanned, ShouldEqual, 3*len(haystack))
+				})
+			})
+
+			Convey("WHEN filtered searches only need the matches of the automaton", func() {
+				automaton.FindAllFiltered(haystack, nil)
+				automaton.FindFirstFiltered(haystack, nil)
+				automaton.IsMatchFiltered(haystack, nil)
+
+				Convey("THEN every search is reported once", func() {
+					So(events, ShouldHaveLength, 3)
+					So(events[1].ShortCircuited, ShouldBeTrue)
+					So(automaton.Stats().Searches, ShouldEqual, 3)
+				})
+			})
+		})
+	}
+}
+
+func TestPatternSet(t *testing.T) {
+	Convey("GIVEN a pattern set", t, func() {
+		set := NewPatternSet(3, 64, 200)
+
+		Convey("THEN it contains exactly the added IDs", func() {
+			So(set.Contains(3), ShouldBeTrue)
+			So(set.Contains(4), ShouldBeFalse)
+			So(set.Contains(1000), ShouldBeFalse)
+			So(set.Len(), ShouldEqual, 3)
+			So(set.IDs(), ShouldResemble, []uint{3, 64, 200})
+		})
+
+		Convey("WHEN an ID is removed", func() {
+			set.Remove(64)
+
+			Convey("THEN it is no longer contained", func() {
+				So(set.IDs(), ShouldResemble, []uint{3, 200})
+			})
+		})
+	})
+
+	Convey("GIVEN a set of all patterns", t, func() {
+		set := NewPatternSetAll(70)
+
+		Convey("THEN it contains every ID below the count", func() {
+			So(set.Len(), ShouldEqual, 70)
+			So(set.Contains(69), ShouldBeTrue)
+			So(set.Contains(70), ShouldBeFalse)
+		})
+	})
+}
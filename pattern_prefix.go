@@ -0,0 +1,128 @@
+package ahocorasick
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// PatternOrder is the order in which [AhoCorasick.PatternsWithPrefix] returns pattern IDs.
+type PatternOrder int
+
+const (
+	// PatternOrderLexicographic orders patterns lexicographically by their bytes, and equal patterns by ID.
+	PatternOrderLexicographic PatternOrder = iota + 1
+	// PatternOrderInsertion orders patterns by ID, i.e. in the order they were given to the builder.
+	PatternOrderInsertion
+)
+
+// patternIndex is the list of pattern IDs of an automaton sorted by pattern, which is built on first use.
+//
+// The native automaton already holds the patterns in a trie, but the aho-corasick crate does not expose its states
+// nor a way to walk them, so prefix queries cannot be answered from it and need this separate index. It costs one
+// uint per pattern. The keys are the retained patterns themselves, except for ASCII case insensitive automatons,
+// which also keep a lower case copy of every pattern containing an upper case ASCII letter, plus a string header
+// per pattern.
+type patternIndex struct {
+	once sync.Once
+	// The pattern of every ID, in lower case if the automaton is ASCII case insensitive.
+	keys []string
+	// The pattern IDs sorted by key and then by ID.
+	sorted []uint
+}
+
+// HasPrefix reports whether any pattern of this automaton starts with prefix.
+//
+// Like [AhoCorasick.PatternsWithPrefix], this is a query over the patterns rather than a search.
+func (ac *AhoCorasick) HasPrefix(prefix string) bool {
+	index := ac.patternIndex()
+	start, end := index.prefixRange(ac.prefixKey(prefix))
+	return start < end
+}
+
+// PatternsWithPrefix returns the IDs of at most limit patterns of this automaton that start with prefix, e.g. for
+// autocompletion, in the given order. If limit is negative, all of them are returned.
+//
+// If this automaton is ASCII case insensitive, so is the comparison with prefix, and lexicographic order ignores
+// the case of ASCII letters. The query uses an index of the patterns sorted lexicographically, which is built on
+// first use and kept for the lifetime of this automaton, since the native automaton cannot be queried by prefix.
+// Finding the patterns takes time logarithmic in the number of patterns, plus time linear in the number of patterns
+// starting with prefix, which are sorted by ID for [PatternOrderInsertion].
+// PatternsWithPrefix panics if order is not valid.
+func (ac *AhoCorasick) PatternsWithPrefix(prefix string, limit int, order PatternOrder) []uint {
+	if order != PatternOrderLexicographic && order != PatternOrderInsertion {
+		panic(fmt.Sprintf("ahocorasick: invalid pattern order %d", order))
+	}
+	index := ac.patternIndex()
+	start, end := index.prefixRange(ac.prefixKey(prefix))
+	ids := make([]uint, end-start)
+	copy(ids, index.sorted[start:end])
+	if order == PatternOrderInsertion {
+		sort.Slice(ids, func(i, j int) bool {
+			return ids[i] < ids[j]
+		})
+	}
+	if limit >= 0 && limit < len(ids) {
+		ids = ids[:limit]
+	}
+	return ids
+}
+
+// patternIndex returns the index of the patterns of ac, building it on first use.
+func (ac *AhoCorasick) patternIndex() *patternIndex {
+	index := &ac.prefixIndex
+	index.once.Do(func() {
+		patterns := ac.patterns
+		index.keys = patterns
+		if ac.config.AsciiCaseInsensitive {
+			index.keys = make([]string, len(patterns))
+			for i, pattern := range patterns {
+				index.keys[i] = ac.prefixKey(pattern)
+			}
+		}
+		index.sorted = make([]uint, len(patterns))
+		for i := range index.sorted {
+			index.sorted[i] = uint(i)
+		}
+		sort.SliceStable(index.sorted, func(i, j int) bool {
+			return index.keys[index.sorted[i]] < index.keys[index.sorted[j]]
+		})
+	})
+	return index
+}
+
+// prefixKey returns the key of s in the pattern index of ac.
+func (ac *AhoCorasick) prefixKey(s string) string {
+	if !ac.config.AsciiCaseInsensitive || !hasASCIIUpper(s) {
+		return s
+	}
+	key := []byte(s)
+	for i, c := range key {
+		if isASCIIUpper(c) {
+			key[i] = c | 0x20
+		}
+	}
+	return string(key)
+}
+
+// hasASCIIUpper reports whether s contains an upper case ASCII letter.
+func hasASCIIUpper(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if isASCIIUpper(s[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// prefixRange returns the range of index.sorted holding the patterns whose key starts with prefix.
+func (index *patternIndex) prefixRange(prefix string) (int, int) {
+	start := sort.Search(len(index.sorted), func(i int) bool {
+		return index.keys[index.sorted[i]] >= prefix
+	})
+	end := start + sort.Search(len(index.sorted)-start, func(i int) bool {
+		return !strings.HasPrefix(index.keys[index.sorted[start+i]], prefix)
+	})
+	return start, end
+}
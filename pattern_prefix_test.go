@@ -0,0 +1,74 @@
+package ahocorasick
+
+import (
+	"fmt"
+	. "github.com/smartystreets/goconvey/convey"
+	"math/rand"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func ExampleAhoCorasick_PatternsWithPrefix() {
+	words := []string{"banana", "apple", "apricot", "avocado", "application"}
+	automaton := NewAhoCorasick(words)
+	for _, id := range automaton.PatternsWithPrefix("ap", 2, PatternOrderLexicographic) {
+		fmt.Println(words[id])
+	}
+	// Output:
+	// apple
+	// application
+}
+
+func TestPatternsWithPrefix(t *testing.T) {
+	Convey("GIVEN random patterns and prefixes", t, func() {
+		random := rand.New(rand.NewSource(1))
+
+		Convey("THEN the patterns starting with the prefix are returned in the requested order", func() {
+			for _, caseInsensitive := range []bool{false, true} {
+				for i := 0; i < 200; i++ {
+					patterns := randomPatterns(random, random.Intn(20), 4)
+					prefix := randomPatterns(random, 1, 2)[0]
+					limit := random.Intn(5) - 1
+					key := func(s string) string {
+						if caseInsensitive {
+							return strings.ToLower(s)
+						}
+						return s
+					}
+					var expected []uint
+					for id, pattern := range patterns {
+						if strings.HasPrefix(key(pattern), key(prefix)) {
+							expected = append(expected, uint(id))
+						}
+					}
+					automaton := NewAhoCorasickBuilder().SetAsciiCaseInsensitive(caseInsensitive).Build(patterns)
+					So(automaton.HasPrefix(prefix), ShouldEqual, len(expected) > 0)
+
+					insertion := expected
+					if limit >= 0 && limit < len(insertion) {
+						insertion = insertion[:limit]
+					}
+					So(automaton.PatternsWithPrefix(prefix, limit, PatternOrderInsertion), ShouldResemble, append([]uint{}, insertion...))
+
+					sort.SliceStable(expected, func(i, j int) bool {
+						return key(patterns[expected[i]]) < key(patterns[expected[j]])
+					})
+					if limit >= 0 && limit < len(expected) {
+						expected = expected[:limit]
+					}
+					So(automaton.PatternsWithPrefix(prefix, limit, PatternOrderLexicographic), ShouldResemble, append([]uint{}, expected...))
+				}
+			}
+		})
+	})
+
+	Convey("GIVEN an automaton that is not case insensitive", t, func() {
+		automaton := NewAhoCorasick([]string{"foo", "bar"})
+
+		Convey("THEN its pattern index reuses the retained patterns as keys", func() {
+			So(automaton.HasPrefix("fo"), ShouldBeTrue)
+			So(&automaton.patternIndex().keys[0] == &automaton.patterns[0], ShouldBeTrue)
+		})
+	})
+}
@@ -0,0 +1,85 @@
+package ahocorasick
+
+import (
+	"math/bits"
+)
+
+// PatternSet is a set of pattern IDs, i.e. of values of [Match.PatternIndex], represented as a bitset.
+//
+// The zero value is an empty set ready to use. A PatternSet must not be modified while it is being used by a search.
+type PatternSet struct {
+	words []uint64
+}
+
+// NewPatternSet creates a set containing the given pattern IDs.
+func NewPatternSet(ids ...uint) *PatternSet {
+	set := &PatternSet{}
+	for _, id := range ids {
+		set.Add(id)
+	}
+	return set
+}
+
+// NewPatternSetAll creates a set containing the pattern IDs 0 to count-1, i.e. all patterns of an automaton
+// built with count patterns.
+func NewPatternSetAll(count int) *PatternSet {
+	set := &PatternSet{words: make([]uint64, (count+63)/64)}
+	for i := range set.words {
+		set.words[i] = ^uint64(0)
+	}
+	if count%64 != 0 {
+		set.words[len(set.words)-1] = 1<<(count%64) - 1
+	}
+	return set
+}
+
+// Add adds id to the set.
+func (s *PatternSet) Add(id uint) {
+	word := int(id / 64)
+	for len(s.words) <= word {
+		s.words = append(s.words, 0)
+	}
+	s.words[word] |= 1 << (id % 64)
+}
+
+// Remove removes id from the set.
+func (s *PatternSet) Remove(id uint) {
+	if word := int(id / 64); word < len(s.words) {
+		s.words[word] &^= 1 << (id % 64)
+	}
+}
+
+// Contains reports whether id is in the set.
+func (s *PatternSet) Contains(id uint) bool {
+	word := int(id / 64)
+	return word < len(s.words) && s.words[word]&(1<<(id%64)) != 0
+}
+
+// Len returns the number of pattern IDs in the set.
+func (s *PatternSet) Len() int {
+	count := 0
+	for _, word := range s.words {
+		count += bits.OnesCount64(word)
+	}
+	return count
+}
+
+// IDs returns the pattern IDs in the set in increasing order.
+func (s *PatternSet) IDs() []uint {
+	ids := make([]uint, 0, s.Len())
+	for i, word := range s.words {
+		for word != 0 {
+			ids = append(ids, uint(i*64+bits.TrailingZeros64(word)))
+			word &= word - 1
+		}
+	}
+	return ids
+}
+
+// Clone returns a copy of the set. The copy of a nil set is nil.
+func (s *PatternSet) Clone() *PatternSet {
+	if s == nil {
+		return nil
+	}
+	return &PatternSet{words: append([]uint64(nil), s.words...)}
+}
@@ -0,0 +1,136 @@
+package ahocorasick
+
+import (
+	"fmt"
+	"sort"
+)
+
+// MatchPosition is the position of the start of a match in terms of lines and columns.
+//
+// Lines are terminated by "\n" or "\r\n"; a lone "\r" does not end a line. Lines and columns are numbered from 1.
+type MatchPosition struct {
+	// The match.
+	Match Match
+	// The line number of the start of the match.
+	Line int
+	// The column of the start of the match, counted in bytes.
+	ByteColumn int
+	// The column of the start of the match, counted in runes. Every byte that is not a UTF-8 continuation byte counts
+	// as a rune, so this agrees with [utf8.RuneCountInString] for valid UTF-8.
+	RuneColumn int
+	// The offset of the first byte of the line in the haystack.
+	LineStart uint
+	// The offset of the end of the line in the haystack, excluding the line terminator.
+	LineEnd uint
+}
+
+// FindAllPositions returns the matches found by [AhoCorasick.FindAll] together with their line and column.
+func (ac *AhoCorasick) FindAllPositions(input string) []MatchPosition {
+	return Positions(input, ac.FindAll(input))
+}
+
+// Positions returns the line and column of the start of every match, in a single pass over the haystack.
+// The positions are ordered by the start of their match. Positions panics with an error wrapping [ErrInvalidSpan]
+// if a match starts after the end of the haystack.
+func Positions(input string, matches []Match) []MatchPosition {
+	sorted := append([]Match(nil), matches...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Start < sorted[j].Start
+	})
+	var tracker LineTracker
+	return append(tracker.Advance(input, sorted), tracker.Flush()...)
+}
+
+// LineTracker computes the line and column of matches in a haystack that is read in chunks, e.g. from a stream.
+//
+// The position of a match is known once the line it starts on has ended, since it includes the end of the line,
+// so positions are returned by the call to [LineTracker.Advance] that reads the end of the line, or by
+// [LineTracker.Flush] for the last line. The zero value is a tracker at the start of a haystack, ready to use.
+type LineTracker struct {
+	// The number of bytes read so far.
+	offset uint
+	// The number of line terminators read so far.
+	lines int
+	// The offset of the start of the current line.
+	lineStart uint
+	// The number of runes read on the current line.
+	runes int
+	// Whether the last byte read was a "\r".
+	lastCR bool
+	// The matches starting at or after offset, in order.
+	queued []Match
+	// The positions of the matches on the current line, whose end is not known yet.
+	pending []MatchPosition
+}
+
+// Advance reads the next chunk of the haystack, with the matches starting in it or later, which must be sorted by
+// their start. The offsets of the matches are relative to the start of the haystack, not of the chunk.
+//
+// Advance returns the positions of the matches, given to this or previous calls, on the lines that ended in chunk.
+func (t *LineTracker) Advance(chunk string, matches []Match) []MatchPosition {
+	t.queued = append(t.queued, matches...)
+	var done []MatchPosition
+	for i := 0; i < len(chunk); i++ {
+		t.positionQueued()
+		c := chunk[i]
+		switch {
+		case c == '\n':
+			end := t.offset
+			if t.lastCR {
+				end--
+			}
+			done = t.endLine(done, end)
+		case c&0xC0 != 0x80:
+			t.runes++
+		}
+		t.lastCR = c == '\r'
+		t.offset++
+	}
+	return done
+}
+
+// Flush ends the haystack and returns the positions of the remaining matches, which are on its last line.
+// The tracker is reset, so it can be reused for another haystack.
+// Flush panics with an error wrapping [ErrInvalidSpan] if a match starts after the end of the haystack.
+func (t *LineTracker) Flush() []MatchPosition {
+	t.positionQueued()
+	if len(t.queued) > 0 {
+		panic(fmt.Errorf("%w: a match starts at %d, after the end of a haystack of length %d", ErrInvalidSpan, t.queued[0].Start, t.offset))
+	}
+	done := t.endLine(nil, t.offset)
+	*t = LineTracker{}
+	return done
+}
+
+// positionQueued computes the position of the queued matches starting at the current offset, except for the end
+// of their line.
+func (t *LineTracker) positionQueued() {
+	for len(t.queued) > 0 && t.queued[0].Start <= t.offset {
+		match := t.queued[0]
+		if match.Start < t.offset {
+			panic(fmt.Errorf("%w: a match starts at %d, which was read already", ErrInvalidSpan, match.Start))
+		}
+		t.pending = append(t.pending, MatchPosition{
+			Match:      match,
+			Line:       t.lines + 1,
+			ByteColumn: int(t.offset-t.lineStart) + 1,
+			RuneColumn: t.runes + 1,
+			LineStart:  t.lineStart,
+		})
+		t.queued = t.queued[1:]
+	}
+}
+
+// endLine completes the positions of the current line, which ends at end, appends them to done and starts a new line
+// after the current byte.
+func (t *LineTracker) endLine(done []MatchPosition, end uint) []MatchPosition {
+	for _, position := range t.pending {
+		position.LineEnd = end
+		done = append(done, position)
+	}
+	t.pending = t.pending[:0]
+	t.lines++
+	t.lineStart = t.offset + 1
+	t.runes = 0
+	return done
+}
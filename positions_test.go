@@ -0,0 +1,87 @@
+package ahocorasick
+
+import (
+	"fmt"
+	. "github.com/smartystreets/goconvey/convey"
+	"math/rand"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func ExampleAhoCorasick_FindAllPositions() {
+	automaton := NewAhoCorasick([]string{"TODO"})
+	for _, position := range automaton.FindAllPositions("package main\r\n\r\n// héllo TODO\n") {
+		fmt.Println(position.Line, position.ByteColumn, position.RuneColumn)
+	}
+	// Output: 3 11 10
+}
+
+// naivePosition computes the position of match by rescanning the haystack.
+func naivePosition(haystack string, match Match) MatchPosition {
+	lineStart := strings.LastIndexByte(haystack[:match.Start], '\n') + 1
+	lineEnd := len(haystack)
+	if i := strings.IndexByte(haystack[lineStart:], '\n'); i >= 0 {
+		lineEnd = lineStart + i
+		if lineEnd > lineStart && haystack[lineEnd-1] == '\r' {
+			lineEnd--
+		}
+	}
+	return MatchPosition{
+		Match:      match,
+		Line:       strings.Count(haystack[:match.Start], "\n") + 1,
+		ByteColumn: int(match.Start) - lineStart + 1,
+		RuneColumn: utf8.RuneCountInString(haystack[lineStart:match.Start]) + 1,
+		LineStart:  uint(lineStart),
+		LineEnd:    uint(lineEnd),
+	}
+}
+
+func TestPositions(t *testing.T) {
+	Convey("GIVEN random haystacks with line terminators and multi-byte runes", t, func() {
+		random := rand.New(rand.NewSource(1))
+		pieces := []string{"a", "b", "é", "\n", "\r\n", "\r", "ab"}
+
+		Convey("THEN the positions agree with a rescan of the haystack, whether it is streamed or not", func() {
+			for i := 0; i < 300; i++ {
+				var builder strings.Builder
+				for j := random.Intn(20); j > 0; j-- {
+					builder.WriteString(pieces[random.Intn(len(pieces))])
+				}
+				haystack := builder.String()
+				automaton := NewAhoCorasick([]string{"a", "\r", "\n", "", "é"})
+				matches := automaton.FindAll(haystack)
+				expected := make([]MatchPosition, len(matches))
+				for j, match := range matches {
+					expected[j] = naivePosition(haystack, match)
+				}
+				actual := automaton.FindAllPositions(haystack)
+				So(actual, ShouldResemble, expected)
+
+				var tracker LineTracker
+				var streamed []MatchPosition
+				remaining := matches
+				for start := 0; start < len(haystack); {
+					end := start + 1 + random.Intn(4)
+					if end > len(haystack) {
+						end = len(haystack)
+					}
+					n := 0
+					for n < len(remaining) && remaining[n].Start < uint(end) {
+						n++
+					}
+					streamed = append(streamed, tracker.Advance(haystack[start:end], remaining[:n])...)
+					remaining = remaining[n:]
+					start = end
+				}
+				streamed = append(streamed, tracker.Advance("", remaining)...)
+				streamed = append(streamed, tracker.Flush()...)
+				if len(expected) == 0 {
+					So(streamed, ShouldBeEmpty)
+				} else {
+					So(streamed, ShouldResemble, expected)
+				}
+			}
+		})
+	})
+}
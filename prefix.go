@@ -0,0 +1,106 @@
+package ahocorasick
+
+/*
+#include "./ahocorasick_rs.h"
+#include <stdlib.h>
+*/
+import "C"
+import (
+	"runtime"
+	"unsafe"
+)
+
+// AllPrefixes returns a match for every pattern that is a prefix of the haystack, ordered by length.
+// Every match starts at 0 and its End is the length of the pattern. If several patterns are equal, only the one
+// with the lowest ID is reported.
+//
+// This is a lookup in a prefix table, e.g. a routing table or a table of phone number prefixes, rather than a
+// substring search: it uses an anchored automaton with [MatchKindLeftMostLongest] semantics, so the search stops
+// as soon as no pattern can match anymore, regardless of the length of the haystack. The match kind of this
+// automaton does not matter. The anchored automaton is built on first use and kept for the lifetime of this
+// automaton, unless this automaton itself uses leftmost-longest semantics and supports anchored searches.
+//
+// Observers are told that the lookup short-circuited if a prefix was found; see [SearchEvent.ShortCircuited].
+func (ac *AhoCorasick) AllPrefixes(input string) []Match {
+	span := ac.beginSearch(SearchMethodPrefix)
+	result := ac.prefixes(input)
+	span.end(len(input), len(result), len(result) > 0)
+	return result
+}
+
+// LongestPrefix returns the longest pattern that is a prefix of the haystack, as a match starting at 0 whose End is
+// the length of the pattern, and whether there is one. If several patterns are the longest prefix, the one with
+// the lowest ID is returned. See [AhoCorasick.AllPrefixes] for details.
+func (ac *AhoCorasick) LongestPrefix(input string) (Match, bool) {
+	span := ac.beginSearch(SearchMethodPrefix)
+	if ac.config.UTF8Aligned {
+		// The longest prefix may end inside a character while a shorter one does not.
+		prefixes := ac.prefixes(input)
+		if len(prefixes) == 0 {
+			span.end(len(input), 0, false)
+			return Match{}, false
+		}
+		span.end(len(input), 1, true)
+		return prefixes[len(prefixes)-1], true
+	}
+	automaton := ac.anchoredLongest()
+	cText := (*C.char)(unsafe.Pointer(unsafe.StringData(input)))
+	cErr := C.int(0)
+	match := C.find_prefix(automaton.native(), cText, C.size_t(len(input)), &cErr)
+	runtime.KeepAlive(cText)
+	runtime.KeepAlive(input)
+	runtime.KeepAlive(automaton)
+	checkNativeSearch(cErr)
+	if match == nil {
+		span.end(len(input), 0, false)
+		return Match{}, false
+	}
+	defer C.free(unsafe.Pointer(match))
+	span.end(len(input), 1, true)
+	return Match{
+		End:          uint(match.end),
+		PatternIndex: uint(match.pattern_index),
+		Start:        uint(match.start),
+	}, true
+}
+
+// prefixes returns the matches of all patterns that are a prefix of input, ordered by length.
+func (ac *AhoCorasick) prefixes(input string) []Match {
+	automaton := ac.anchoredLongest()
+	cText := (*C.char)(unsafe.Pointer(unsafe.StringData(input)))
+	foundCount := C.long(0)
+	cErr := C.int(0)
+	cMatches := C.find_prefixes(automaton.native(), cText, C.size_t(len(input)), &foundCount, &cErr)
+	runtime.KeepAlive(cText)
+	runtime.KeepAlive(input)
+	runtime.KeepAlive(automaton)
+	checkNativeSearch(cErr)
+	result := takeCMatches(cMatches, foundCount)
+	// The native library reports the longest prefix first.
+	for i, j := 0, len(result)-1; i < j; i, j = i+1, j-1 {
+		result[i], result[j] = result[j], result[i]
+	}
+	if ac.config.UTF8Aligned {
+		result = filterAligned(result, alignedIn(input))
+	}
+	return result
+}
+
+// anchoredLongest returns an automaton for the same patterns and settings as ac that supports anchored searches
+// using [MatchKindLeftMostLongest] semantics: ac itself if possible, otherwise an anchored automaton that is built
+// on first use.
+func (ac *AhoCorasick) anchoredLongest() *AhoCorasick {
+	if ac.config.MatchKind == MatchKindLeftMostLongest && ac.config.StartKind != StartKindUnanchored {
+		return ac
+	}
+	ac.companionMu.Lock()
+	defer ac.companionMu.Unlock()
+	if ac.anchored == nil {
+		builder := NewAhoCorasickBuilderFromConfig(ac.config).
+			SetMatchKind(MatchKindLeftMostLongest).
+			SetStartKind(StartKindAnchored).
+			SetUTF8Aligned(false)
+		ac.anchored = builder.Build(ac.patterns)
+	}
+	return ac.anchored
+}
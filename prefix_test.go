@@ -0,0 +1,91 @@
+package ahocorasick
+
+import (
+	"fmt"
+	. "github.com/smartystreets/goconvey/convey"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func ExampleAhoCorasick_LongestPrefix() {
+	routes := []string{"/", "/api/", "/api/v1/", "/static/"}
+	automaton := NewAhoCorasick(routes)
+	match, ok := automaton.LongestPrefix("/api/v1/users")
+	fmt.Println(routes[match.PatternIndex], ok)
+	// Output: /api/v1/ true
+}
+
+func TestPrefixes(t *testing.T) {
+	Convey("GIVEN random patterns and haystacks", t, func() {
+		random := rand.New(rand.NewSource(1))
+
+		Convey("THEN AllPrefixes returns the patterns that are prefixes of the haystack", func() {
+			for _, matchKind := range []MatchKind{MatchKindStandard, MatchKindLeftMostFirst, MatchKindLeftMostLongest} {
+				for _, startKind := range []StartKind{StartKindUnanchored, StartKindAnchored, StartKindBoth} {
+					for i := 0; i < 50; i++ {
+						patterns := randomPatterns(random, 1+random.Intn(6), 3)
+						haystack := randomPatterns(random, 1, 6)[0]
+						var expected []Match
+						for length := 0; length <= len(haystack); length++ {
+							for id, pattern := range patterns {
+								if len(pattern) == length && strings.HasPrefix(haystack, pattern) {
+									expected = append(expected, Match{End: uint(length), PatternIndex: uint(id)})
+									break
+								}
+							}
+						}
+						automaton := NewAhoCorasickBuilder().SetMatchKind(matchKind).SetStartKind(startKind).Build(patterns)
+						actual := automaton.AllPrefixes(haystack)
+						longest, ok := automaton.LongestPrefix(haystack)
+						if len(expected) == 0 {
+							So(actual, ShouldBeEmpty)
+							So(ok, ShouldBeFalse)
+						} else {
+							So(actual, ShouldResemble, expected)
+							So(ok, ShouldBeTrue)
+							So(longest, ShouldResemble, expected[len(expected)-1])
+						}
+					}
+				}
+			}
+		})
+	})
+
+	Convey("GIVEN duplicate patterns", t, func() {
+		automaton := NewAhoCorasick([]string{"ab", "a", "ab"})
+
+		Convey("THEN only the one with the lowest ID is reported", func() {
+			longest, ok := automaton.LongestPrefix("abc")
+			So(ok, ShouldBeTrue)
+			So(longest, ShouldResemble, Match{End: 2, PatternIndex: 0, Start: 0})
+			So(automaton.AllPrefixes("abc"), ShouldResemble, []Match{{End: 1, PatternIndex: 1}, {End: 2, PatternIndex: 0}})
+		})
+	})
+
+	Convey("GIVEN observed automatons", t, func() {
+		var events []SearchEvent
+		observer := ObserverFunc(func(event SearchEvent) {
+			events = append(events, event)
+		})
+		automatons := []*AhoCorasick{
+			NewAhoCorasickBuilder().SetObserver(observer).Build([]string{"foo", "foobar"}),
+			NewAhoCorasickBuilder().SetObserver(observer).SetUTF8Aligned(true).Build([]string{"foo", "foobar"}),
+		}
+
+		Convey("THEN prefix lookups are only reported as short-circuited when a prefix was found", func() {
+			for _, automaton := range automatons {
+				events = nil
+				automaton.AllPrefixes("foobaz")
+				automaton.AllPrefixes("bar")
+				automaton.LongestPrefix("foobaz")
+				automaton.LongestPrefix("bar")
+				So(events, ShouldHaveLength, 4)
+				So(events[0].ShortCircuited, ShouldBeTrue)
+				So(events[1].ShortCircuited, ShouldBeFalse)
+				So(events[2].ShortCircuited, ShouldBeTrue)
+				So(events[3].ShortCircuited, ShouldBeFalse)
+			}
+		})
+	})
+}
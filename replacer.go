@@ -0,0 +1,164 @@
+package ahocorasick
+
+import (
+	"io"
+	"strings"
+)
+
+// Replacer replaces a list of strings with replacements, like [strings.Replacer], using an [AhoCorasick] automaton
+// with [MatchKindLeftMostFirst] semantics. It is safe for concurrent use by multiple goroutines.
+//
+// Replacer is a drop-in replacement for [strings.Replacer] that scales to large lists of old strings: the whole
+// haystack is searched in a single pass of the automaton, regardless of the number of old strings.
+type Replacer struct {
+	automaton *AhoCorasick
+	// The replacement of every pattern of the automaton, indexed by pattern index.
+	values []string
+	// The argument index of every pattern of the automaton, i.e. its priority: lower indexes win.
+	priorities []int
+	// The priority and replacement of the first empty old string, if hasEmpty is set.
+	emptyPriority int
+	emptyValue    string
+	hasEmpty      bool
+}
+
+// NewReplacer returns a new [Replacer] from a list of old, new string pairs, with the same semantics as
+// [strings.NewReplacer]: replacements are performed in the order they appear in the target string, without
+// overlapping matches, and comparisons are done in argument order, so an earlier old string wins over a later one
+// matching at the same position, even if the later one is longer. An empty old string matches at every position
+// that is not consumed by a match of an earlier old string.
+//
+// NewReplacer panics if given an odd number of arguments.
+func NewReplacer(oldnew ...string) *Replacer {
+	if len(oldnew)%2 == 1 {
+		panic("ahocorasick: NewReplacer: odd argument count")
+	}
+	r := &Replacer{}
+	var patterns []string
+	for i := 0; i < len(oldnew); i += 2 {
+		if oldnew[i] == "" {
+			if !r.hasEmpty {
+				r.emptyPriority, r.emptyValue, r.hasEmpty = i/2, oldnew[i+1], true
+			}
+			continue
+		}
+		patterns = append(patterns, oldnew[i])
+		r.values = append(r.values, oldnew[i+1])
+		r.priorities = append(r.priorities, i/2)
+	}
+	r.automaton = NewAhoCorasickBuilder().SetMatchKind(MatchKindLeftMostFirst).Build(patterns)
+	return r
+}
+
+// Replace returns a copy of s with all replacements performed.
+func (r *Replacer) Replace(s string) string {
+	if !r.hasEmpty {
+		matches := r.automaton.FindAll(s)
+		if len(matches) == 0 {
+			return s
+		}
+		var buf strings.Builder
+		buf.Grow(len(s))
+		_, _ = r.writeMatches(&buf, s, matches)
+		return buf.String()
+	}
+	var buf strings.Builder
+	buf.Grow(len(s) + (len(s)+1)*len(r.emptyValue))
+	_, _ = r.writeWithEmpty(&buf, s)
+	return buf.String()
+}
+
+// WriteString writes s to w with all replacements performed.
+func (r *Replacer) WriteString(w io.Writer, s string) (n int, err error) {
+	sw, ok := w.(io.StringWriter)
+	if !ok {
+		sw = stringWriter{w}
+	}
+	if !r.hasEmpty {
+		return r.writeMatches(sw, s, r.automaton.FindAll(s))
+	}
+	return r.writeWithEmpty(sw, s)
+}
+
+// writeMatches writes s to w, replacing the given non-overlapping matches.
+func (r *Replacer) writeMatches(w io.StringWriter, s string, matches []Match) (n int, err error) {
+	last := 0
+	for _, match := range matches {
+		if err = writeAll(w, &n, s[last:match.Start], r.values[match.PatternIndex]); err != nil {
+			return n, err
+		}
+		last = int(match.End)
+	}
+	err = writeAll(w, &n, s[last:])
+	return n, err
+}
+
+// writeWithEmpty writes s to w with all replacements performed, when one of the old strings is empty.
+//
+// At every position, the empty old string is replaced unless a non-empty old string of higher priority matches
+// there. In the latter case only that one is replaced; otherwise the best non-empty old string matching at the same
+// position, if any, is replaced right after the empty one, and the search continues after it.
+func (r *Replacer) writeWithEmpty(w io.StringWriter, s string) (n int, err error) {
+	var next *Match
+	for i := 0; ; {
+		if next != nil && int(next.Start) < i {
+			next = nil
+		}
+		if next == nil {
+			next = r.automaton.FindFirstIn(s, i, len(s))
+			if next == nil {
+				// Keep the cached match valid until the end of the haystack.
+				next = &Match{Start: uint(len(s)) + 1}
+			}
+		}
+		matchesHere := int(next.Start) == i
+		if matchesHere && r.priorities[next.PatternIndex] < r.emptyPriority {
+			if err = writeAll(w, &n, r.values[next.PatternIndex]); err != nil {
+				return n, err
+			}
+			i = int(next.End)
+			continue
+		}
+		if err = writeAll(w, &n, r.emptyValue); err != nil {
+			return n, err
+		}
+		if matchesHere {
+			if err = writeAll(w, &n, r.values[next.PatternIndex]); err != nil {
+				return n, err
+			}
+			i = int(next.End)
+			continue
+		}
+		if i == len(s) {
+			return n, nil
+		}
+		if err = writeAll(w, &n, s[i:i+1]); err != nil {
+			return n, err
+		}
+		i++
+	}
+}
+
+// writeAll writes the given strings to w, adding the number of bytes written to n.
+func writeAll(w io.StringWriter, n *int, values ...string) error {
+	for _, value := range values {
+		if value == "" {
+			continue
+		}
+		written, err := w.WriteString(value)
+		*n += written
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// stringWriter adapts an [io.Writer] to an [io.StringWriter].
+type stringWriter struct {
+	w io.Writer
+}
+
+func (w stringWriter) WriteString(s string) (int, error) {
+	return w.w.Write([]byte(s))
+}
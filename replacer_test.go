@@ -0,0 +1,59 @@
+package ahocorasick
+
+import (
+	"bytes"
+	"fmt"
+	. "github.com/smartystreets/goconvey/convey"
+	"io"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func ExampleNewReplacer() {
+	replacer := NewReplacer("<", "&lt;", ">", "&gt;")
+	fmt.Println(replacer.Replace("This is <b>HTML</b>!"))
+	// Output: This is &lt;b&gt;HTML&lt;/b&gt;!
+}
+
+// writerOnly hides the WriteString method of the wrapped writer.
+type writerOnly struct {
+	io.Writer
+}
+
+func TestReplacer(t *testing.T) {
+	Convey("GIVEN random old and new strings", t, func() {
+		random := rand.New(rand.NewSource(1))
+
+		Convey("THEN the replacer agrees with strings.Replacer", func() {
+			for i := 0; i < 2000; i++ {
+				oldnew := randomPatterns(random, 2*random.Intn(7), 3)
+				haystack := randomPatterns(random, 1, 30)[0]
+				expected := strings.NewReplacer(oldnew...).Replace(haystack)
+				replacer := NewReplacer(oldnew...)
+				So(replacer.Replace(haystack), ShouldEqual, expected)
+
+				var buf bytes.Buffer
+				n, err := replacer.WriteString(writerOnly{&buf}, haystack)
+				So(err, ShouldBeNil)
+				So(n, ShouldEqual, len(expected))
+				So(buf.String(), ShouldEqual, expected)
+			}
+		})
+	})
+
+	Convey("GIVEN an empty old string", t, func() {
+		replacer := NewReplacer("a", "1", "", "X", "b", "2")
+
+		Convey("THEN it is replaced at every position not consumed by an earlier old string", func() {
+			So(replacer.Replace("abc"), ShouldEqual, "1X2XcX")
+			So(replacer.Replace(""), ShouldEqual, "X")
+		})
+	})
+
+	Convey("GIVEN an odd number of arguments", t, func() {
+		Convey("THEN NewReplacer panics", func() {
+			So(func() { NewReplacer("a") }, ShouldPanic)
+		})
+	})
+}
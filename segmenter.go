@@ -0,0 +1,130 @@
+package ahocorasick
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+// SegmentationDirection selects the dictionary matching algorithm used by a [Segmenter].
+type SegmentationDirection int
+
+const (
+	// SegmentationForward selects forward maximum matching: from the start of the text, the longest word starting
+	// at the current position is taken.
+	SegmentationForward SegmentationDirection = iota + 1
+	// SegmentationBackward selects backward maximum matching: from the end of the text, the longest word ending
+	// at the current position is taken.
+	SegmentationBackward
+	// SegmentationBidirectional runs both forward and backward maximum matching and keeps the better tokenization:
+	// the one with fewer segments, then the one with fewer single-rune segments, then the backward one.
+	SegmentationBidirectional
+)
+
+// Segmenter tokenizes text using a word dictionary and maximum matching, as commonly done for languages written
+// without spaces between words, such as Chinese or Japanese.
+//
+// Forward maximum matching is exactly a non-overlapping search with [MatchKindLeftMostLongest] semantics, and
+// backward maximum matching is the same search over the reversed text, using the reversed automaton described in
+// [AhoCorasick.FindLast]. A Segmenter is safe for concurrent use.
+type Segmenter struct {
+	automaton *AhoCorasick
+	direction SegmentationDirection
+}
+
+// NewSegmenter creates a [Segmenter] for the given dictionary. The PatternIndex of the matches it reports is the
+// index of the word in words.
+//
+// An error is returned if a word is empty or not valid UTF-8, or if direction is not valid.
+func NewSegmenter(words []string, direction SegmentationDirection) (*Segmenter, error) {
+	if direction < SegmentationForward || direction > SegmentationBidirectional {
+		return nil, fmt.Errorf("ahocorasick: invalid segmentation direction %d", direction)
+	}
+	for i, word := range words {
+		if word == "" || !utf8.ValidString(word) {
+			return nil, fmt.Errorf("ahocorasick: word %d (%q) is empty or not valid UTF-8", i, word)
+		}
+	}
+	automaton, err := NewAhoCorasickBuilder().SetMatchKind(MatchKindLeftMostLongest).TryBuild(words)
+	if err != nil {
+		return nil, err
+	}
+	return &Segmenter{automaton: automaton, direction: direction}, nil
+}
+
+// Close releases the native memory held by the automaton of this segmenter. See [AhoCorasick.Close].
+func (s *Segmenter) Close() error {
+	return s.automaton.Close()
+}
+
+// Segment returns a complete tokenization of text: the segments cover the whole text in order. Dictionary words are
+// reported with their [Match], and text not covered by any word is reported rune by rune with a nil Match, so a
+// segment never splits a UTF-8 encoded rune. Invalid UTF-8 bytes are reported one by one.
+func (s *Segmenter) Segment(text string) []Segment {
+	switch s.direction {
+	case SegmentationForward:
+		return s.forward(text)
+	case SegmentationBackward:
+		return s.backward(text)
+	}
+	forward, backward := s.forward(text), s.backward(text)
+	if len(forward) != len(backward) {
+		if len(forward) < len(backward) {
+			return forward
+		}
+		return backward
+	}
+	if singleRuneSegments(forward) < singleRuneSegments(backward) {
+		return forward
+	}
+	return backward
+}
+
+// forward tokenizes text using forward maximum matching.
+func (s *Segmenter) forward(text string) []Segment {
+	return wordSegments(text, s.automaton.FindAll(text))
+}
+
+// backward tokenizes text using backward maximum matching.
+func (s *Segmenter) backward(text string) []Segment {
+	n := uint(len(text))
+	reversed := s.automaton.reverse().FindAll(reverseString(text))
+	matches := make([]Match, len(reversed))
+	for i, match := range reversed {
+		matches[len(reversed)-1-i] = Match{End: n - match.Start, PatternIndex: match.PatternIndex, Start: n - match.End}
+	}
+	return wordSegments(text, matches)
+}
+
+// wordSegments returns the segments of the given matches of dictionary words, sorted by position,
+// and of the runes of the text between them.
+func wordSegments(text string, matches []Match) []Segment {
+	segments := make([]Segment, 0, len(matches))
+	last := uint(0)
+	for i := range matches {
+		match := &matches[i]
+		segments = appendRunes(segments, text, last, match.Start)
+		segments = append(segments, Segment{Text: text[match.Start:match.End], Start: match.Start, End: match.End, Match: match})
+		last = match.End
+	}
+	return appendRunes(segments, text, last, uint(len(text)))
+}
+
+// appendRunes appends a segment for every rune of text[start:end].
+func appendRunes(segments []Segment, text string, start uint, end uint) []Segment {
+	for start < end {
+		_, size := utf8.DecodeRuneInString(text[start:end])
+		segments = append(segments, Segment{Text: text[start : start+uint(size)], Start: start, End: start + uint(size)})
+		start += uint(size)
+	}
+	return segments
+}
+
+func singleRuneSegments(segments []Segment) int {
+	count := 0
+	for _, segment := range segments {
+		if utf8.RuneCountInString(segment.Text) == 1 {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,85 @@
+package ahocorasick
+
+import (
+	"fmt"
+	. "github.com/smartystreets/goconvey/convey"
+	"strings"
+	"testing"
+)
+
+func ExampleSegmenter() {
+	segmenter, err := NewSegmenter([]string{"研究", "研究生", "生命", "起源"}, SegmentationBackward)
+	if err != nil {
+		panic(err)
+	}
+	var words []string
+	for _, segment := range segmenter.Segment("研究生命起源") {
+		words = append(words, segment.Text)
+	}
+	fmt.Println(strings.Join(words, "/"))
+	// Output: 研究/生命/起源
+}
+
+// segmentTexts returns the texts of the segments.
+func segmentTexts(segments []Segment) []string {
+	texts := make([]string, len(segments))
+	for i, segment := range segments {
+		texts[i] = segment.Text
+	}
+	return texts
+}
+
+func TestSegmenter(t *testing.T) {
+	words := []string{"研究", "研究生", "生命", "起源", "结合", "合成", "分子"}
+
+	Convey("GIVEN a forward segmenter", t, func() {
+		segmenter, err := NewSegmenter(words, SegmentationForward)
+		So(err, ShouldBeNil)
+
+		Convey("THEN the longest word is taken from the start", func() {
+			So(segmentTexts(segmenter.Segment("研究生命起源")), ShouldResemble, []string{"研究生", "命", "起源"})
+		})
+
+		Convey("THEN unknown text is emitted rune by rune", func() {
+			segments := segmenter.Segment("我的研究x")
+			So(segmentTexts(segments), ShouldResemble, []string{"我", "的", "研究", "x"})
+			So(segments[0].Match, ShouldBeNil)
+			So(segments[2].Match, ShouldResemble, &Match{End: 12, PatternIndex: 0, Start: 6})
+		})
+
+		Convey("THEN invalid UTF-8 is emitted byte by byte", func() {
+			So(segmentTexts(segmenter.Segment("\xff\xfe研究")), ShouldResemble, []string{"\xff", "\xfe", "研究"})
+		})
+	})
+
+	Convey("GIVEN a backward segmenter", t, func() {
+		segmenter, err := NewSegmenter(words, SegmentationBackward)
+		So(err, ShouldBeNil)
+
+		Convey("THEN the longest word is taken from the end", func() {
+			So(segmentTexts(segmenter.Segment("研究生命起源")), ShouldResemble, []string{"研究", "生命", "起源"})
+		})
+	})
+
+	Convey("GIVEN a bidirectional segmenter", t, func() {
+		segmenter, err := NewSegmenter(words, SegmentationBidirectional)
+		So(err, ShouldBeNil)
+
+		Convey("THEN the tokenization with fewer single-rune segments is kept", func() {
+			So(segmentTexts(segmenter.Segment("研究生命起源")), ShouldResemble, []string{"研究", "生命", "起源"})
+		})
+
+		Convey("THEN ties are broken in favour of the backward tokenization", func() {
+			// Forward: 结合/成/分子; backward: 结/合成/分子. Both have three segments and one single rune,
+			// so the backward tokenization wins.
+			So(segmentTexts(segmenter.Segment("结合成分子")), ShouldResemble, []string{"结", "合成", "分子"})
+		})
+	})
+
+	Convey("GIVEN an empty word", t, func() {
+		Convey("THEN the segmenter cannot be created", func() {
+			_, err := NewSegmenter([]string{"研究", ""}, SegmentationForward)
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
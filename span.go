@@ -0,0 +1,125 @@
+package ahocorasick
+
+/*
+#include "./ahocorasick_rs.h"
+#include <stdlib.h>
+*/
+import "C"
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"unsafe"
+)
+
+// ErrInvalidSpan is wrapped by the panic value used when a search is restricted to a span that is not within
+// the haystack.
+var ErrInvalidSpan = errors.New("ahocorasick: invalid span")
+
+// FindAllIn is like [AhoCorasick.FindAll], but only searches input[start:end]. The offsets of the matches are
+// relative to the start of input, not to start.
+//
+// A match never extends outside the span, even if a pattern would match across one of its boundaries.
+// FindAllIn panics with an error wrapping [ErrInvalidSpan] unless 0 <= start <= end <= len(input), and with an error
+// wrapping [ErrUnsupportedSearch] if this automaton only supports anchored searches.
+func (ac *AhoCorasick) FindAllIn(input string, start int, end int) []Match {
+	ac.requireUnanchored("FindAllIn")
+	checkSpan(input, start, end)
+	span := ac.beginSearch(SearchMethodFindAll)
+	result := ac.findAllIn(input, start, end)
+	span.end(end-start, len(result), false)
+	return result
+}
+
+// FindFirstIn is like [AhoCorasick.FindFirst], but only searches input[start:end].
+// See [AhoCorasick.FindAllIn] for details.
+func (ac *AhoCorasick) FindFirstIn(input string, start int, end int) *Match {
+	ac.requireUnanchored("FindFirstIn")
+	checkSpan(input, start, end)
+	span := ac.beginSearch(SearchMethodFindFirst)
+	match := ac.findFirstIn(input, start, end)
+	if match == nil {
+		span.end(end-start, 0, false)
+		return nil
+	}
+	span.end(end-start, 1, true)
+	return match
+}
+
+// IsMatchIn is like [AhoCorasick.IsMatch], but only searches input[start:end].
+// See [AhoCorasick.FindAllIn] for details.
+func (ac *AhoCorasick) IsMatchIn(input string, start int, end int) bool {
+	ac.requireUnanchored("IsMatchIn")
+	checkSpan(input, start, end)
+	span := ac.beginSearch(SearchMethodIsMatch)
+	found := ac.isMatchIn(input, start, end)
+	matchCount := 0
+	if found {
+		matchCount = 1
+	}
+	span.end(end-start, matchCount, found)
+	return found
+}
+
+// findAllIn searches input[start:end] for non-overlapping matches without measuring the search.
+func (ac *AhoCorasick) findAllIn(input string, start int, end int) []Match {
+	cText := (*C.char)(unsafe.Pointer(unsafe.StringData(input)))
+	foundCount := C.long(0)
+	cErr := C.int(0)
+	cMatches := C.find_iter_in(ac.native(), cText, C.size_t(len(input)), C.size_t(start), C.size_t(end), &foundCount, &cErr)
+	runtime.KeepAlive(cText)
+	runtime.KeepAlive(input)
+	runtime.KeepAlive(ac)
+	checkNativeSearch(cErr)
+	result := takeCMatches(cMatches, foundCount)
+	if ac.config.UTF8Aligned {
+		result = ac.realign(input, start, end, result, -1, alignedIn(input))
+	}
+	return result
+}
+
+// findFirstIn searches input[start:end] for the first match without measuring the search.
+func (ac *AhoCorasick) findFirstIn(input string, start int, end int) *Match {
+	cText := (*C.char)(unsafe.Pointer(unsafe.StringData(input)))
+	cErr := C.int(0)
+	match := C.find_in(ac.native(), cText, C.size_t(len(input)), C.size_t(start), C.size_t(end), &cErr)
+	runtime.KeepAlive(cText)
+	runtime.KeepAlive(input)
+	runtime.KeepAlive(ac)
+	checkNativeSearch(cErr)
+	if match == nil {
+		return nil
+	}
+	defer C.free(unsafe.Pointer(match))
+	result := &Match{
+		End:          uint(match.end),
+		PatternIndex: uint(match.pattern_index),
+		Start:        uint(match.start),
+	}
+	if ac.config.UTF8Aligned {
+		result = firstOf(ac.realign(input, start, end, []Match{*result}, 1, alignedIn(input)))
+	}
+	return result
+}
+
+// isMatchIn reports whether input[start:end] contains a match without measuring the search.
+func (ac *AhoCorasick) isMatchIn(input string, start int, end int) bool {
+	cText := (*C.char)(unsafe.Pointer(unsafe.StringData(input)))
+	cErr := C.int(0)
+	isMatch := C.is_match_in(ac.native(), cText, C.size_t(len(input)), C.size_t(start), C.size_t(end), &cErr)
+	runtime.KeepAlive(cText)
+	runtime.KeepAlive(input)
+	runtime.KeepAlive(ac)
+	checkNativeSearch(cErr)
+	if int(isMatch) != 0 && ac.config.UTF8Aligned {
+		return ac.findFirstIn(input, start, end) != nil
+	}
+	return int(isMatch) != 0
+}
+
+// checkSpan panics unless [start, end) is a valid span of input.
+func checkSpan(input string, start int, end int) {
+	if start < 0 || start > end || end > len(input) {
+		panic(fmt.Errorf("%w: [%d, %d) is not within a haystack of length %d", ErrInvalidSpan, start, end, len(input)))
+	}
+}
@@ -0,0 +1,62 @@
+package ahocorasick
+
+import (
+	"errors"
+	"fmt"
+	. "github.com/smartystreets/goconvey/convey"
+	"math/rand"
+	"testing"
+)
+
+func ExampleAhoCorasick_FindAllIn() {
+	automaton := NewAhoCorasick([]string{"Host:", "Accept:"})
+	request := "GET / HTTP/1.1\r\nHost: example.com\r\nAccept: */*\r\n\r\nHost: not a header"
+	headers := len("GET / HTTP/1.1\r\n")
+	body := len(request) - len("Host: not a header")
+	fmt.Println(automaton.FindAllIn(request, headers, body))
+	// Output: [{21 0 16} {42 1 35}]
+}
+
+func TestFindAllIn(t *testing.T) {
+	Convey("GIVEN random patterns, haystacks and spans", t, func() {
+		random := rand.New(rand.NewSource(1))
+
+		Convey("THEN searching a span is like searching a slice, with absolute offsets", func() {
+			for _, matchKind := range []MatchKind{MatchKindStandard, MatchKindLeftMostFirst, MatchKindLeftMostLongest} {
+				for i := 0; i < 200; i++ {
+					patterns := randomPatterns(random, 1+random.Intn(6), 3)
+					haystack := randomPatterns(random, 1, 30)[0]
+					start := random.Intn(len(haystack) + 1)
+					end := start + random.Intn(len(haystack)-start+1)
+					automaton := NewAhoCorasickBuilder().SetMatchKind(matchKind).Build(patterns)
+					expected := automaton.FindAll(haystack[start:end])
+					for j := range expected {
+						expected[j].Start += uint(start)
+						expected[j].End += uint(start)
+					}
+					actual := automaton.FindAllIn(haystack, start, end)
+					if len(expected) == 0 {
+						So(actual, ShouldBeEmpty)
+						So(automaton.FindFirstIn(haystack, start, end), ShouldBeNil)
+						So(automaton.IsMatchIn(haystack, start, end), ShouldBeFalse)
+					} else {
+						So(actual, ShouldResemble, expected)
+						So(automaton.FindFirstIn(haystack, start, end), ShouldResemble, &expected[0])
+						So(automaton.IsMatchIn(haystack, start, end), ShouldBeTrue)
+					}
+				}
+			}
+		})
+	})
+
+	Convey("GIVEN a span that is not within the haystack", t, func() {
+		automaton := NewAhoCorasick([]string{"foo"})
+
+		Convey("THEN the search panics", func() {
+			defer func() {
+				So(errors.Is(recover().(error), ErrInvalidSpan), ShouldBeTrue)
+			}()
+			automaton.FindAllIn("foo", 2, 4)
+		})
+	})
+}
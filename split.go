@@ -0,0 +1,91 @@
+package ahocorasick
+
+// Split slices the haystack into the substrings separated by the matches found by [AhoCorasick.FindAll], using
+// the match semantics of this automaton, and returns a slice of the substrings between those separators.
+//
+// Like [strings.SplitN], the count determines the number of substrings to return:
+//   - n > 0: at most n substrings; the last substring will be the unsplit remainder;
+//   - n == 0: the result is nil (zero substrings);
+//   - n < 0: all substrings.
+//
+// Unlike [strings.SplitN], empty matches do not split the haystack, since they do not separate anything.
+// When n > 0, the search stops after the (n-1)-th separator.
+func (ac *AhoCorasick) Split(input string, n int) []string {
+	if n == 0 {
+		return nil
+	}
+	separators := ac.separators(input, n-1)
+	result := make([]string, 0, len(separators)+1)
+	last := uint(0)
+	for _, separator := range separators {
+		result = append(result, input[last:separator.Start])
+		last = separator.End
+	}
+	return append(result, input[last:])
+}
+
+// separators returns the first limit non-empty matches in input, or all of them if limit is negative.
+func (ac *AhoCorasick) separators(input string, limit int) []Match {
+	var matches []Match
+	if limit < 0 {
+		matches = ac.FindAll(input)
+	} else {
+		var truncated bool
+		matches, truncated = ac.FindN(input, limit)
+		if truncated && hasEmptyMatch(matches) {
+			// Empty matches do not count, so more matches may be needed.
+			matches = ac.FindAll(input)
+		}
+	}
+	separators := matches[:0]
+	for _, match := range matches {
+		if match.Start != match.End && len(separators) != limit {
+			separators = append(separators, match)
+		}
+	}
+	return separators
+}
+
+func hasEmptyMatch(matches []Match) bool {
+	for _, match := range matches {
+		if match.Start == match.End {
+			return true
+		}
+	}
+	return false
+}
+
+// Segment is a part of a haystack returned by [AhoCorasick.Segments]: either a match or the text between matches.
+type Segment struct {
+	// The text of the segment.
+	Text string
+	// The starting position of the segment in the haystack.
+	Start uint
+	// The ending position of the segment in the haystack.
+	End uint
+	// The match this segment is made of, or nil if the segment is text between matches.
+	Match *Match
+}
+
+// Segments splits the haystack into segments alternating between the text between matches and the matches found by
+// [AhoCorasick.FindAll], using the match semantics of this automaton.
+//
+// The segments cover the whole haystack in order. Text between matches is only reported if it is not empty,
+// so two matches may follow each other directly. Empty matches are reported like other matches.
+func (ac *AhoCorasick) Segments(input string) []Segment {
+	matches := ac.FindAll(input)
+	segments := make([]Segment, 0, 2*len(matches)+1)
+	last := uint(0)
+	for i := range matches {
+		match := &matches[i]
+		if match.Start > last {
+			segments = append(segments, Segment{Text: input[last:match.Start], Start: last, End: match.Start})
+		}
+		segments = append(segments, Segment{Text: input[match.Start:match.End], Start: match.Start, End: match.End, Match: match})
+		last = match.End
+	}
+	if last < uint(len(input)) {
+		segments = append(segments, Segment{Text: input[last:], Start: last, End: uint(len(input))})
+	}
+	return segments
+}
@@ -0,0 +1,86 @@
+package ahocorasick
+
+import (
+	"fmt"
+	. "github.com/smartystreets/goconvey/convey"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func ExampleAhoCorasick_Split() {
+	automaton := NewAhoCorasickBuilder().
+		SetMatchKind(MatchKindLeftMostFirst).
+		Build([]string{", ", ";", " and "})
+	fmt.Printf("%q\n", automaton.Split("apples, pears;plums and cherries", -1))
+	fmt.Printf("%q\n", automaton.Split("apples, pears;plums and cherries", 2))
+	// Output:
+	// ["apples" "pears" "plums" "cherries"]
+	// ["apples" "pears;plums and cherries"]
+}
+
+func ExampleAhoCorasick_Segments() {
+	automaton := NewAhoCorasick([]string{"{name}", "{day}"})
+	for _, segment := range automaton.Segments("Hello {name}, happy {day}!") {
+		fmt.Printf("%q %v\n", segment.Text, segment.Match != nil)
+	}
+	// Output:
+	// "Hello " false
+	// "{name}" true
+	// ", happy " false
+	// "{day}" true
+	// "!" false
+}
+
+func TestSplit(t *testing.T) {
+	Convey("GIVEN random separators and haystacks", t, func() {
+		random := rand.New(rand.NewSource(1))
+
+		Convey("THEN splitting with a single separator agrees with strings.SplitN", func() {
+			for i := 0; i < 300; i++ {
+				separator := randomPatterns(random, 1, 2)[0] + "a"
+				haystack := randomPatterns(random, 1, 30)[0]
+				automaton := NewAhoCorasick([]string{separator})
+				for n := -1; n < 6; n++ {
+					So(automaton.Split(haystack, n), ShouldResemble, strings.SplitN(haystack, separator, n))
+				}
+			}
+		})
+
+		Convey("THEN the segments cover the haystack and agree with FindAll", func() {
+			for _, matchKind := range []MatchKind{MatchKindStandard, MatchKindLeftMostFirst, MatchKindLeftMostLongest} {
+				for i := 0; i < 100; i++ {
+					patterns := randomPatterns(random, 1+random.Intn(6), 3)
+					haystack := randomPatterns(random, 1, 30)[0]
+					automaton := NewAhoCorasickBuilder().SetMatchKind(matchKind).Build(patterns)
+					var text strings.Builder
+					var matches []Match
+					for _, segment := range automaton.Segments(haystack) {
+						So(segment.Text, ShouldEqual, haystack[segment.Start:segment.End])
+						So(segment.Start, ShouldEqual, uint(text.Len()))
+						text.WriteString(segment.Text)
+						if segment.Match != nil {
+							matches = append(matches, *segment.Match)
+						}
+					}
+					So(text.String(), ShouldEqual, haystack)
+					if expected := automaton.FindAll(haystack); len(expected) == 0 {
+						So(matches, ShouldBeEmpty)
+					} else {
+						So(matches, ShouldResemble, expected)
+					}
+				}
+			}
+		})
+	})
+
+	Convey("GIVEN an automaton with an empty pattern", t, func() {
+		automaton := NewAhoCorasickBuilder().SetMatchKind(MatchKindLeftMostFirst).Build([]string{"-", ""})
+
+		Convey("THEN empty matches do not split the haystack", func() {
+			So(automaton.Split("a-b-c", -1), ShouldResemble, []string{"a", "b", "c"})
+			So(automaton.Split("a-b-c", 2), ShouldResemble, []string{"a", "b-c"})
+			So(automaton.Split("abc", 2), ShouldResemble, []string{"abc"})
+		})
+	})
+}
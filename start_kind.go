@@ -1,5 +1,7 @@
 package ahocorasick
 
+import "fmt"
+
 // The kind of anchored starting configurations to support in an Aho-Corasick searcher.
 //
 // Depending on which searcher is used internally by AhoCorasick, supporting both unanchored and anchored searches can be quite costly. For this reason, AhoCorasickBuilder::start_kind can be used to configure whether your searcher supports unanchored, anchored or both kinds of searches.
@@ -14,3 +16,36 @@ const (
 	StartKindUnanchored StartKind = 2 // Support only unanchored searches. Requesting an anchored search will return an error in fallible APIs and panic in infallible APIs.
 	StartKindAnchored   StartKind = 3 // Support only anchored searches. Requesting an unanchored search will return an error in fallible APIs and panic in infallible APIs.
 )
+
+// String returns the textual name of the start kind, e.g. "unanchored".
+func (k StartKind) String() string {
+	switch k {
+	case StartKindBoth:
+		return "both"
+	case StartKindUnanchored:
+		return "unanchored"
+	case StartKindAnchored:
+		return "anchored"
+	}
+	return fmt.Sprintf("StartKind(%d)", int(k))
+}
+
+// MarshalText implements [encoding.TextMarshaler] using the names returned by [StartKind.String].
+func (k StartKind) MarshalText() ([]byte, error) {
+	switch k {
+	case StartKindBoth, StartKindUnanchored, StartKindAnchored:
+		return []byte(k.String()), nil
+	}
+	return nil, fmt.Errorf("ahocorasick: invalid start kind %d", int(k))
+}
+
+// UnmarshalText implements [encoding.TextUnmarshaler]. It accepts the names returned by [StartKind.String].
+func (k *StartKind) UnmarshalText(text []byte) error {
+	for _, kind := range []StartKind{StartKindBoth, StartKindUnanchored, StartKindAnchored} {
+		if string(text) == kind.String() {
+			*k = kind
+			return nil
+		}
+	}
+	return fmt.Errorf("ahocorasick: unknown start kind %q", text)
+}
@@ -0,0 +1,131 @@
+package ahocorasick
+
+import (
+	"expvar"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// AutomatonStats is a snapshot describing an [AhoCorasick] automaton and the searches it performed.
+type AutomatonStats struct {
+	// The name given by [AhoCorasickBuilder.SetName].
+	Name string `json:"name"`
+	// The kind of the underlying automaton.
+	Kind AhoCorasickKind `json:"kind"`
+	// The number of patterns the automaton was built with.
+	PatternCount int `json:"pattern_count"`
+	// The native memory used by the automaton. See [AhoCorasick.MemoryUsage].
+	NativeBytes uint `json:"native_bytes"`
+	// The number of searches performed so far.
+	Searches uint64 `json:"searches"`
+	// The total length of all haystacks searched so far.
+	BytesScanned uint64 `json:"bytes_scanned"`
+	// The total number of matches reported so far.
+	Matches uint64 `json:"matches"`
+}
+
+// searchCounters accumulates the search statistics of a single automaton.
+// It is allocated separately from the automaton so that the registry can refer to it without keeping the automaton alive.
+type searchCounters struct {
+	searches     atomic.Uint64
+	bytesScanned atomic.Uint64
+	matches      atomic.Uint64
+}
+
+func (c *searchCounters) record(haystackLen int, matchCount int) {
+	c.searches.Add(1)
+	c.bytesScanned.Add(uint64(haystackLen))
+	c.matches.Add(uint64(matchCount))
+}
+
+// registryEntry holds everything needed to describe a registered automaton after it was built,
+// without referring to the automaton itself.
+type registryEntry struct {
+	name         string
+	kind         AhoCorasickKind
+	patternCount int
+	nativeBytes  uint
+}
+
+var registry = struct {
+	sync.Mutex
+	entries map[*searchCounters]registryEntry
+}{entries: make(map[*searchCounters]registryEntry)}
+
+func register(ac *AhoCorasick) {
+	entry := registryEntry{
+		name:         ac.name,
+		kind:         ac.kind,
+		patternCount: ac.patternCount,
+		nativeBytes:  ac.nativeBytes,
+	}
+	registry.Lock()
+	registry.entries[ac.counters] = entry
+	registry.Unlock()
+}
+
+func unregister(counters *searchCounters) {
+	registry.Lock()
+	delete(registry.entries, counters)
+	registry.Unlock()
+}
+
+func (e registryEntry) stats(counters *searchCounters) AutomatonStats {
+	return AutomatonStats{
+		Name:         e.name,
+		Kind:         e.kind,
+		PatternCount: e.patternCount,
+		NativeBytes:  e.nativeBytes,
+		Searches:     counters.searches.Load(),
+		BytesScanned: counters.bytesScanned.Load(),
+		Matches:      counters.matches.Load(),
+	}
+}
+
+// Stats returns a snapshot describing this automaton and the searches it performed so far.
+func (ac *AhoCorasick) Stats() AutomatonStats {
+	entry := registryEntry{
+		name:         ac.name,
+		kind:         ac.kind,
+		patternCount: ac.patternCount,
+		nativeBytes:  ac.nativeBytes,
+	}
+	return entry.stats(ac.counters)
+}
+
+// RegisteredAutomata returns the statistics of every live automaton that was given a name using
+// [AhoCorasickBuilder.SetName], sorted by name.
+//
+// An automaton is removed from the registry once it has been garbage collected.
+func RegisteredAutomata() []AutomatonStats {
+	registry.Lock()
+	result := make([]AutomatonStats, 0, len(registry.entries))
+	for counters, entry := range registry.entries {
+		result = append(result, entry.stats(counters))
+	}
+	registry.Unlock()
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+// PublishExpvar publishes the result of [RegisteredAutomata] as an [expvar] variable with the given name,
+// which makes it available at /debug/vars when the expvar handler is installed.
+//
+// Like [expvar.Publish], it panics if a variable with the same name is already published.
+func PublishExpvar(name string) {
+	expvar.Publish(name, expvar.Func(func() any {
+		return RegisteredAutomata()
+	}))
+}
+
+// traceRegionType returns the runtime/trace region type used for searches performed using method by a searcher
+// with the given name.
+func traceRegionType(name string, method SearchMethod) string {
+	if name == "" {
+		return "ahocorasick." + string(method)
+	}
+	return "ahocorasick." + string(method) + "(" + name + ")"
+}
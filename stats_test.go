@@ -0,0 +1,47 @@
+package ahocorasick
+
+import (
+	"encoding/json"
+	"expvar"
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+func TestStats(t *testing.T) {
+	Convey("GIVEN a named automaton", t, func() {
+		automaton, err := New([]string{"foo", "bar"}, WithName("TestStats"), WithKind(AhoCorasickKindDFA))
+		So(err, ShouldBeNil)
+
+		Convey("WHEN it performs searches", func() {
+			automaton.FindAll("foo bar foo")
+			automaton.IsMatch("quux")
+
+			Convey("THEN its stats reflect the searches", func() {
+				stats := automaton.Stats()
+				So(stats.Name, ShouldEqual, "TestStats")
+				So(stats.Kind, ShouldEqual, AhoCorasickKindDFA)
+				So(stats.PatternCount, ShouldEqual, 2)
+				So(stats.NativeBytes, ShouldBeGreaterThan, 0)
+				So(stats.Searches, ShouldEqual, 2)
+				So(stats.BytesScanned, ShouldEqual, 15)
+				So(stats.Matches, ShouldEqual, 3)
+			})
+
+			Convey("THEN the registry reports the same stats", func() {
+				So(RegisteredAutomata(), ShouldContain, automaton.Stats())
+			})
+		})
+
+		Convey("WHEN the registry is published via expvar", func() {
+			if expvar.Get("TestStats") == nil {
+				PublishExpvar("TestStats")
+			}
+			var published []AutomatonStats
+			So(json.Unmarshal([]byte(expvar.Get("TestStats").String()), &published), ShouldBeNil)
+
+			Convey("THEN the automaton is listed", func() {
+				So(published, ShouldContain, automaton.Stats())
+			})
+		})
+	})
+}
@@ -0,0 +1,62 @@
+package ahocorasick
+
+import (
+	"unicode/utf8"
+)
+
+// isUTF8Boundary reports whether offset is not in the middle of a UTF-8 encoded character of input, i.e. whether
+// it is not right before a continuation byte. Invalid UTF-8 bytes other than continuation bytes are boundaries.
+func isUTF8Boundary(input string, offset uint) bool {
+	return offset >= uint(len(input)) || utf8.RuneStart(input[offset])
+}
+
+// alignedIn returns a function reporting whether a match starts and ends on UTF-8 boundaries of input.
+func alignedIn(input string) func(Match) bool {
+	return func(match Match) bool {
+		return isUTF8Boundary(input, match.Start) && isUTF8Boundary(input, match.End)
+	}
+}
+
+// realign returns matches if they are all aligned, otherwise the matches that a non-overlapping search of
+// input[start:end] would report without the candidates that are not aligned. At most limit matches are returned,
+// unless limit is negative.
+func (ac *AhoCorasick) realign(input string, start int, end int, matches []Match, limit int, aligned func(Match) bool) []Match {
+	if allMatch(matches, aligned) {
+		return matches
+	}
+	return selectNonOverlapping(ac.alignedCandidates(input, start, end, aligned), ac.config.MatchKind, limit)
+}
+
+// alignedCandidates returns the overlapping matches in input[start:end] that are aligned, using the automaton
+// returned by [AhoCorasick.overlapping]. The search is not measured.
+func (ac *AhoCorasick) alignedCandidates(input string, start int, end int, aligned func(Match) bool) []Match {
+	return filterAligned(ac.overlapping().findOverlappingIn(input, start, end), aligned)
+}
+
+// filterAligned removes the matches that are not aligned, in place.
+func filterAligned(matches []Match, aligned func(Match) bool) []Match {
+	filtered := matches[:0]
+	for _, match := range matches {
+		if aligned(match) {
+			filtered = append(filtered, match)
+		}
+	}
+	return filtered
+}
+
+// firstOf returns the first of matches, or nil if there is none.
+func firstOf(matches []Match) *Match {
+	if len(matches) == 0 {
+		return nil
+	}
+	return &matches[0]
+}
+
+func allMatch(matches []Match, predicate func(Match) bool) bool {
+	for _, match := range matches {
+		if !predicate(match) {
+			return false
+		}
+	}
+	return true
+}
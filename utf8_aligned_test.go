@@ -0,0 +1,142 @@
+package ahocorasick
+
+import (
+	"fmt"
+	. "github.com/smartystreets/goconvey/convey"
+	"math/rand"
+	"testing"
+)
+
+func ExampleAhoCorasickBuilder_SetUTF8Aligned() {
+	// "\xa9" is the last byte of "é" and "©", so it matches inside both of them.
+	patterns := []string{"\xa9", "©"}
+	haystack := "café ©"
+	fmt.Println(NewAhoCorasickBuilder().Build(patterns).FindAll(haystack))
+	fmt.Println(NewAhoCorasickBuilder().SetUTF8Aligned(true).Build(patterns).FindAll(haystack))
+	// Output:
+	// [{5 0 4} {8 1 6}]
+	// [{8 1 6}]
+}
+
+// randomUTF8Strings returns random non-empty strings made of ASCII letters, multi-byte characters and lone
+// UTF-8 lead and continuation bytes.
+func randomUTF8Strings(random *rand.Rand, count int, maxPieces int) []string {
+	pieces := []string{"a", "A", "é", "É", "\xc3", "\xa9", "\xa9a"}
+	strs := make([]string, count)
+	for i := range strs {
+		for j := 1 + random.Intn(maxPieces); j > 0; j-- {
+			strs[i] += pieces[random.Intn(len(pieces))]
+		}
+	}
+	return strs
+}
+
+func TestUTF8Aligned(t *testing.T) {
+	Convey("GIVEN random patterns and haystacks whose matches may split characters", t, func() {
+		random := rand.New(rand.NewSource(1))
+
+		Convey("THEN searches report the matches of an automaton without the misaligned candidates", func() {
+			for _, matchKind := range []MatchKind{MatchKindStandard, MatchKindLeftMostFirst, MatchKindLeftMostLongest} {
+				for _, caseInsensitive := range []bool{false, true} {
+					for i := 0; i < 200; i++ {
+						patterns := randomUTF8Strings(random, 1+random.Intn(5), 2)
+						haystack := randomUTF8Strings(random, 1, 12)[0]
+						builder := NewAhoCorasickBuilder().SetAsciiCaseInsensitive(caseInsensitive)
+						aligned := alignedIn(haystack)
+						candidates := filterAligned(builder.Clone().Build(patterns).FindOverlapping(haystack), aligned)
+						expected := selectNonOverlapping(append([]Match(nil), candidates...), matchKind, -1)
+						automaton := builder.Clone().SetMatchKind(matchKind).SetUTF8Aligned(true).Build(patterns)
+
+						So(automaton.Count(haystack), ShouldEqual, len(expected))
+						So(automaton.CountByPattern(haystack), ShouldResemble, countByPattern(expected, len(patterns)))
+						So(automaton.CountOverlapping(haystack), ShouldEqual, len(candidates))
+						So(automaton.FindLast(haystack), ShouldResemble, expectedLastMatch(append([]Match(nil), candidates...), matchKind))
+						if len(expected) == 0 {
+							So(automaton.FindAll(haystack), ShouldBeEmpty)
+							So(automaton.FindFirst(haystack), ShouldBeNil)
+							So(automaton.IsMatch(haystack), ShouldBeFalse)
+						} else {
+							So(automaton.FindAll(haystack), ShouldResemble, expected)
+							So(automaton.FindFirst(haystack), ShouldResemble, &expected[0])
+							So(automaton.IsMatch(haystack), ShouldBeTrue)
+						}
+						n := random.Intn(4)
+						found, truncated := automaton.FindN(haystack, n)
+						So(truncated, ShouldEqual, len(expected) > n)
+						if truncated {
+							So(found, ShouldResemble, expected[:n])
+						} else if len(expected) > 0 {
+							So(found, ShouldResemble, expected)
+						}
+						which := &PatternSet{}
+						for _, candidate := range candidates {
+							which.Add(candidate.PatternIndex)
+						}
+						So(automaton.WhichMatch(haystack).IDs(), ShouldResemble, which.IDs())
+						if matchKind == MatchKindStandard {
+							So(automaton.FindOverlapping(haystack), ShouldResemble, candidates)
+						}
+					}
+				}
+			}
+		})
+
+		Convey("THEN span searches only report aligned matches within the span", func() {
+			for _, matchKind := range []MatchKind{MatchKindStandard, MatchKindLeftMostFirst, MatchKindLeftMostLongest} {
+				for i := 0; i < 200; i++ {
+					patterns := randomUTF8Strings(random, 1+random.Intn(5), 2)
+					haystack := randomUTF8Strings(random, 1, 12)[0]
+					start := random.Intn(len(haystack) + 1)
+					end := start + random.Intn(len(haystack)-start+1)
+					var candidates []Match
+					for _, candidate := range NewAhoCorasick(patterns).FindOverlapping(haystack[start:end]) {
+						candidate.Start += uint(start)
+						candidate.End += uint(start)
+						if isUTF8Boundary(haystack, candidate.Start) && isUTF8Boundary(haystack, candidate.End) {
+							candidates = append(candidates, candidate)
+						}
+					}
+					expected := selectNonOverlapping(candidates, matchKind, -1)
+					automaton := NewAhoCorasickBuilder().SetMatchKind(matchKind).SetUTF8Aligned(true).Build(patterns)
+					if len(expected) == 0 {
+						So(automaton.FindAllIn(haystack, start, end), ShouldBeEmpty)
+						So(automaton.IsMatchIn(haystack, start, end), ShouldBeFalse)
+					} else {
+						So(automaton.FindAllIn(haystack, start, end), ShouldResemble, expected)
+						So(automaton.IsMatchIn(haystack, start, end), ShouldBeTrue)
+					}
+				}
+			}
+		})
+	})
+
+	Convey("GIVEN a leftmost-first automaton whose preferred pattern splits a character", t, func() {
+		automaton := NewAhoCorasickBuilder().
+			SetMatchKind(MatchKindLeftMostFirst).
+			SetUTF8Aligned(true).
+			Build([]string{"\xc3", "é"})
+
+		Convey("THEN the rejected candidate does not hide the other pattern", func() {
+			So(automaton.FindAll("café"), ShouldResemble, []Match{{End: 5, PatternIndex: 1, Start: 3}})
+		})
+	})
+
+	Convey("GIVEN prefixes that end inside a character", t, func() {
+		automaton := NewAhoCorasickBuilder().SetUTF8Aligned(true).Build([]string{"caf", "caf\xc3"})
+
+		Convey("THEN only the aligned prefixes are reported", func() {
+			So(automaton.AllPrefixes("café"), ShouldResemble, []Match{{End: 3, PatternIndex: 0, Start: 0}})
+			match, ok := automaton.LongestPrefix("café")
+			So(ok, ShouldBeTrue)
+			So(match, ShouldResemble, Match{End: 3, PatternIndex: 0, Start: 0})
+		})
+	})
+
+	Convey("GIVEN an automaton without the option", t, func() {
+		automaton := NewAhoCorasickBuilder().SetMatchKind(MatchKindLeftMostFirst).Build([]string{"\xc3", "é"})
+
+		Convey("THEN matches may split characters", func() {
+			So(automaton.FindAll("café"), ShouldResemble, []Match{{End: 4, PatternIndex: 0, Start: 3}})
+		})
+	})
+}
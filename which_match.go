@@ -0,0 +1,49 @@
+package ahocorasick
+
+/*
+#include "./ahocorasick_rs.h"
+*/
+import "C"
+import (
+	"runtime"
+	"unsafe"
+)
+
+// WhichMatch returns the set of patterns that occur anywhere in the haystack, like the regexp set APIs found in
+// other languages. Positions are not reported, and no match is allocated.
+//
+// Patterns are found using overlapping semantics regardless of the match kind of this automaton, so a pattern is
+// reported even if, e.g., a leftmost-first search would always prefer another pattern over it. For automatons using
+// leftmost semantics this uses the companion automaton described in [AhoCorasick.FindAllFiltered].
+//
+// The search stops as soon as every pattern has been seen, since the rest of the haystack cannot change the result.
+// With [AhoCorasickBuilder.SetUTF8Aligned] the whole haystack is always searched, since the candidates are collected
+// before the misaligned ones are dropped.
+// WhichMatch panics with an error wrapping [ErrUnsupportedSearch] if this automaton only supports anchored searches.
+func (ac *AhoCorasick) WhichMatch(input string) *PatternSet {
+	automaton := ac.unanchoredOverlapping("WhichMatch")
+	set := &PatternSet{words: make([]uint64, (ac.patternCount+63)/64)}
+	span := ac.beginSearch(SearchMethodWhichMatch)
+	if ac.config.UTF8Aligned {
+		found := 0
+		for _, match := range ac.alignedCandidates(input, 0, len(input), alignedIn(input)) {
+			if !set.Contains(match.PatternIndex) {
+				set.Add(match.PatternIndex)
+				found++
+			}
+		}
+		span.end(len(input), found, false)
+		return set
+	}
+	cText := (*C.char)(unsafe.Pointer(unsafe.StringData(input)))
+	cSet := (*C.uint64_t)(unsafe.Pointer(unsafe.SliceData(set.words)))
+	cErr := C.int(0)
+	found := int(C.which_match(automaton.native(), cText, C.size_t(len(input)), cSet, C.size_t(ac.patternCount), &cErr))
+	runtime.KeepAlive(cText)
+	runtime.KeepAlive(input)
+	runtime.KeepAlive(set)
+	runtime.KeepAlive(automaton)
+	checkNativeSearch(cErr)
+	span.end(len(input), found, found > 0 && found == ac.patternCount)
+	return set
+}
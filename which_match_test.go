@@ -0,0 +1,85 @@
+package ahocorasick
+
+import (
+	"fmt"
+	. "github.com/smartystreets/goconvey/convey"
+	"math/rand"
+	"testing"
+)
+
+func ExampleAhoCorasick_WhichMatch() {
+	automaton := NewAhoCorasickBuilder().
+		SetMatchKind(MatchKindLeftMostFirst).
+		Build([]string{"Samwise", "Sam", "Frodo", "Gandalf"})
+	fmt.Println(automaton.WhichMatch("Samwise and Frodo").IDs())
+	// Output: [0 1 2]
+}
+
+func TestWhichMatch(t *testing.T) {
+	Convey("GIVEN random patterns and haystacks", t, func() {
+		random := rand.New(rand.NewSource(1))
+
+		Convey("THEN WhichMatch returns the patterns of all overlapping matches", func() {
+			for _, matchKind := range []MatchKind{MatchKindStandard, MatchKindLeftMostFirst, MatchKindLeftMostLongest} {
+				for i := 0; i < 200; i++ {
+					patterns := randomPatterns(random, 1+random.Intn(70), 3)
+					haystack := randomPatterns(random, 1, 30)[0]
+					expected := NewPatternSet()
+					for _, match := range NewAhoCorasick(patterns).FindOverlapping(haystack) {
+						expected.Add(match.PatternIndex)
+					}
+					actual := NewAhoCorasickBuilder().SetMatchKind(matchKind).Build(patterns).WhichMatch(haystack)
+					So(actual.IDs(), ShouldResemble, expected.IDs())
+				}
+			}
+		})
+	})
+
+	Convey("GIVEN an observed automaton", t, func() {
+		var events []SearchEvent
+		automaton := NewAhoCorasickBuilder().
+			SetObserver(ObserverFunc(func(event SearchEvent) {
+				events = append(events, event)
+			})).
+			Build([]string{"a", "b"})
+
+		Convey("WHEN every pattern is found", func() {
+			automaton.WhichMatch("abab")
+
+			Convey("THEN the search is reported as short-circuited", func() {
+				So(events, ShouldHaveLength, 1)
+				So(events[0].Method, ShouldEqual, SearchMethodWhichMatch)
+				So(events[0].MatchCount, ShouldEqual, 2)
+				So(events[0].ShortCircuited, ShouldBeTrue)
+			})
+		})
+	})
+
+	Convey("GIVEN an observed automaton whose matches must be aligned on characters", t, func() {
+		var events []SearchEvent
+		automaton := NewAhoCorasickBuilder().
+			SetObserver(ObserverFunc(func(event SearchEvent) {
+				events = append(events, event)
+			})).
+			SetUTF8Aligned(true).
+			Build([]string{"a", "b"})
+
+		Convey("WHEN every pattern is found", func() {
+			automaton.WhichMatch("abab")
+
+			Convey("THEN the search is not reported as short-circuited, since it searched the whole haystack", func() {
+				So(events, ShouldHaveLength, 1)
+				So(events[0].MatchCount, ShouldEqual, 2)
+				So(events[0].ShortCircuited, ShouldBeFalse)
+			})
+		})
+	})
+
+	Convey("GIVEN an automaton without patterns", t, func() {
+		automaton := NewAhoCorasick(nil)
+
+		Convey("THEN no pattern matches", func() {
+			So(automaton.WhichMatch("foo").Len(), ShouldEqual, 0)
+		})
+	})
+}